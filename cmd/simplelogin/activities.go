@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/config"
+)
+
+// activitiesCommand prints one page of an alias' activity log, one event per
+// line: time, action, sender and recipient.
+func activitiesCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	page := fs.Int("page", 0, "Page ID to fetch (starting at 0, newest first)")
+	relative := fs.Bool("relative-time", false, "Show times as in \"3 days ago\" instead of timestamps")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		if *page < 0 {
+			return fail(2, "--page must be >= 0")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		id := ref.id
+		if id <= 0 {
+			a, err := resolveAlias(ctx, c, ref)
+			if err != nil {
+				return fail(1, err)
+			}
+			id = a.ID
+		}
+		activities, err := c.AliasActivities(ctx, id, *page)
+		if err != nil {
+			return fail(1, err)
+		}
+		if len(activities) == 0 {
+			if *page > 0 {
+				infof(os.Stderr, "page %d is empty (the alias may have fewer pages)\n", *page)
+				return exitNoData
+			}
+			infof(os.Stderr, "no activity on this alias\n")
+			return 0
+		}
+		now := time.Now()
+		for _, act := range activities {
+			t := time.Unix(act.Timestamp, 0)
+			when := t.UTC().Format(time.RFC3339)
+			if *relative {
+				when = relativeTime(t, now)
+			}
+			_, _ = fmt.Printf("%s\t%-7s\t%s -> %s\n", when, act.Action, act.From, act.To)
+		}
+		return 0
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// aliasRef holds the flags a command can use to address one alias.
+type aliasRef struct {
+	id           int
+	email        string
+	reverseAlias string
+}
+
+func (r *aliasRef) register(fs *flag.FlagSet) {
+	fs.IntVar(&r.id, "id", 0, "Alias ID")
+	fs.StringVar(&r.email, "email", "", "Alias email (looked up when --id is not given)")
+	fs.StringVar(&r.reverseAlias, "reverse-alias", "", "Reverse-alias address of one of the alias' contacts (slow: scans all contacts)")
+}
+
+func (r aliasRef) empty() bool {
+	return r.id <= 0 && r.email == "" && r.reverseAlias == ""
+}
+
+// resolveAlias fetches the alias addressed by --id or, failing that, --email
+// or --reverse-alias.
+func resolveAlias(ctx context.Context, c *api.Client, ref aliasRef) (api.Alias, error) {
+	switch {
+	case ref.id > 0:
+		return c.GetAlias(ctx, ref.id)
+	case ref.email != "":
+		return c.FindAliasByEmail(ctx, ref.email)
+	case ref.reverseAlias != "":
+		infof(os.Stderr, "scanning contacts for %s...\n", ref.reverseAlias)
+		a, err := c.FindAliasByReverseAlias(ctx, ref.reverseAlias)
+		if errors.Is(err, api.ErrAliasNotFound) {
+			return a, fmt.Errorf("could not resolve reverse alias %s: no contact of any alias uses it", ref.reverseAlias)
+		}
+		return a, err
+	}
+	return api.Alias{}, errors.New("--id, --email or --reverse-alias is required")
+}
+
+func printAliasState(a api.Alias) {
+	state := "disabled"
+	if a.Enabled {
+		state = "enabled"
+	}
+	_, _ = fmt.Printf("%s: %s (forwards=%d blocks=%d replies=%d)\n", a.Email, state, a.NbForward, a.NbBlock, a.NbReply)
+	if a.Note != nil && *a.Note != "" {
+		_, _ = fmt.Println("note:", *a.Note)
+	}
+}
+
+// setEnabledCommand backs the enable, disable and toggle commands. want is nil for toggle.
+func setEnabledCommand(want *bool, fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		timeout := 60 * time.Second
+		if ref.reverseAlias != "" {
+			// scanning every alias' contacts can take a while
+			timeout = 5 * time.Minute
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		if want == nil || a.Enabled != *want {
+			a, err = c.ToggleAlias(ctx, a.ID)
+			if err != nil {
+				return fail(1, err)
+			}
+		}
+		printAliasState(a)
+		return 0
+	}
+}
+
+func enableCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	on := true
+	return setEnabledCommand(&on, fs, cfg)
+}
+
+func disableCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	off := false
+	return setEnabledCommand(&off, fs, cfg)
+}
+
+func toggleCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	return setEnabledCommand(nil, fs, cfg)
+}
+
+func watchCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	var interval time.Duration
+	fs.Var(newDurationFlag(&interval, 5*time.Second), "interval", "How often to poll the alias, e.g. 30s, 5m or 1d")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		if interval < time.Second {
+			return fail(2, "--interval must be at least 1s")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		prev, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		printAliasState(prev)
+		infof(os.Stderr, "watching every %s, press Ctrl-C to stop\n", interval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return 0
+			case <-ticker.C:
+			}
+			cur, err := c.GetAlias(ctx, prev.ID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return 0
+				}
+				fail(1, err) // keep watching; the next poll may succeed
+				continue
+			}
+			var changes []string
+			if cur.Enabled != prev.Enabled {
+				changes = append(changes, fmt.Sprintf("enabled=%v", cur.Enabled))
+			}
+			for _, n := range []struct {
+				name      string
+				prev, cur int
+			}{{"forwards", prev.NbForward, cur.NbForward}, {"blocks", prev.NbBlock, cur.NbBlock}, {"replies", prev.NbReply, cur.NbReply}} {
+				if n.cur != n.prev {
+					changes = append(changes, fmt.Sprintf("%s=%d (%+d)", n.name, n.cur, n.cur-n.prev))
+				}
+			}
+			if len(changes) > 0 {
+				_, _ = fmt.Printf("%s %s\n", time.Now().Format("15:04:05"), strings.Join(changes, " "))
+			}
+			prev = cur
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runBounded calls fn for each index in [0, n) with at most limit calls in
+// flight. Errors are reported on stderr; the number of successful and failed
+// calls is returned.
+func runBounded(n, limit int, fn func(i int) error) (ok, failed int) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := fn(i)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				reportError(1, err.Error(), err)
+				return
+			}
+			ok++
+		}(i)
+	}
+	wg.Wait()
+	return ok, failed
+}
+
+// pacer spaces calls at least interval apart, across goroutines. A zero
+// interval does not wait.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the caller's slot comes up or ctx is done.
+func (p *pacer) wait(ctx context.Context) error {
+	if p.interval <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+	t := time.NewTimer(time.Until(at))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"simplelogincli/pkg/api"
+)
+
+// TestRunBoundedTallies runs concurrent creates against a server that fails
+// every third request and checks the tallies add up; run it with -race.
+func TestRunBoundedTallies(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%3 == 0 {
+			http.Error(w, `{"error":"boom"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1,"email":"a@sl.lan"}`))
+	}))
+	defer ts.Close()
+	c := api.NewClient(ts.URL, "k")
+	c.WithRetries(0, 0)
+
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	const n = 60
+	ok, failed := runBounded(n, 8, func(int) error {
+		_, err := c.CreateRandomAlias(context.Background(), "", "", nil, nil)
+		return err
+	})
+	if ok+failed != n || failed != n/3 {
+		t.Fatalf("ok=%d failed=%d, want %d and %d", ok, failed, n-n/3, n/3)
+	}
+}
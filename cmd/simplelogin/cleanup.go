@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+func cleanupCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	olderThan := fs.String("disabled-older-than", "", "Only consider disabled aliases created before this age, e.g. 90d, 2w or 720h (required)")
+	del := fs.Bool("delete", false, "Delete the matching aliases after confirmation")
+	yes := fs.Bool("yes", false, "Skip the confirmation prompt when deleting")
+	dryRun := fs.Bool("dry-run", false, "Only print what would be deleted")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *olderThan == "" {
+			return fail(2, "--disabled-older-than is required")
+		}
+		age, err := parseDuration(*olderThan)
+		if err != nil {
+			return failf(2, "invalid --disabled-older-than %q: %v\n", *olderThan, err)
+		}
+		cutoff := time.Now().Add(-age)
+
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		var stale []api.Alias
+		for page := 0; ; page++ {
+			res, err := c.ListAliasesFiltered(ctx, page, "", api.AliasFilterDisabled)
+			if err != nil {
+				return fail(1, err)
+			}
+			if len(res.Aliases) == 0 {
+				break
+			}
+			for _, a := range res.Aliases {
+				if !a.Enabled && time.Unix(a.CreationTimestamp, 0).Before(cutoff) {
+					stale = append(stale, a)
+				}
+			}
+			//sleep to avoid rate limiting
+			time.Sleep(700 * time.Millisecond)
+		}
+		if len(stale) == 0 {
+			infof(os.Stderr, "no disabled aliases older than %s\n", *olderThan)
+			return 0
+		}
+		for _, a := range stale {
+			_, _ = fmt.Printf("%s\tid=%d\tcreated=%s\n", a.Email, a.ID, time.Unix(a.CreationTimestamp, 0).Format("2006-01-02"))
+		}
+		if !*del {
+			return 0
+		}
+		if *dryRun {
+			infof(os.Stderr, "dry run: would delete %d aliases\n", len(stale))
+			return 0
+		}
+		if !*yes && !confirm(fmt.Sprintf("Delete %d aliases?", len(stale))) {
+			return fail(1, "aborted")
+		}
+		failed := 0
+		for _, a := range stale {
+			if err := c.DeleteAlias(ctx, a.ID, ""); err != nil {
+				failf(1, "failed to delete %s: %v", a.Email, err)
+				failed++
+				continue
+			}
+			infof(os.Stdout, "Alias deleted: %s\n", a.Email)
+		}
+		if failed > 0 {
+			return 1
+		}
+		return 0
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"simplelogincli/pkg/config"
+)
+
+// command is an entry of the command table that dispatch, the help output and
+// the commands listing are all built from.
+type command struct {
+	name    string   // "contacts list" for a subcommand of the contacts group
+	aliases []string // other names dispatch accepts, in the same form as name
+	summary string   // one line for the help output
+	// define adds the command's flags to fs and returns the function that runs
+	// the command once they are parsed. It must not do anything else, since
+	// the commands listing calls it only to read the flags. A nil define marks
+	// a command group, whose subcommands follow it in the table.
+	define func(fs *flag.FlagSet, cfg config.SecureConfig) func() int
+}
+
+// commands lists every command in the order of the help output.
+var commands = []command{
+	{name: "set-key", summary: "Store API key and base URL", define: setKeyCommand},
+	{name: "config", summary: "Inspect the CLI configuration"},
+	{name: "config path", summary: "Print the config file location", define: configPathCommand},
+	{name: "config env", summary: "Print export lines for eval \"$(simplelogin config env)\"", define: configEnvCommand},
+	{name: "config set-mailboxes", summary: "Store default mailbox IDs for custom, e.g. 3,5 (--clear to remove)", define: configSetMailboxesCommand},
+	{name: "whoami", summary: "Show account info for the current API key", define: whoAmICommand},
+	{name: "settings", summary: "Show or change account settings"},
+	{name: "settings show", summary: "Print the account settings", define: settingsShowCommand},
+	{name: "settings set", summary: "Change account settings (--sender-format)", define: settingsSetCommand},
+	{name: "verify", summary: "Check config file, API key, base URL and API access", define: verifyCommand},
+	{name: "options", summary: "List available alias suffix options", define: optionsCommand},
+	{name: "random", summary: "Create a random alias", define: randomCommand},
+	{name: "custom", summary: "Create a custom alias from prefix + suffix", define: customCommand},
+	{name: "provision", summary: "Create a mailbox (if needed) and an alias delivering to it", define: provisionCommand},
+	{name: "last", summary: "Show the last alias created with --remember", define: lastCommand},
+	{name: "pin", summary: "Pin several aliases by email", define: pinCommand},
+	{name: "unpin", summary: "Unpin several aliases by email", define: unpinCommand},
+	{name: "similar", summary: "Show which suffixes match an existing alias' domain", define: similarCommand},
+	{name: "is-mine", summary: "Check whether an address is one of your aliases", define: isMineCommand},
+	{name: "contacts", summary: "List, add or export an alias' contacts"},
+	{name: "contacts list", summary: "Print an alias' contacts with their reverse aliases", define: contactsListCommand},
+	{name: "contacts add", summary: "Create a contact and print the reverse alias to write to", define: contactsAddCommand},
+	{name: "contacts export", summary: "Write all contacts of an alias as JSON or CSV", define: contactsExportCommand},
+	{name: "import", summary: "Create aliases for email logins in a Bitwarden/1Password export", define: importCommand},
+	{name: "list", summary: "List aliases, one page at a time", define: listCommand},
+	{name: "get", summary: "Show one alias, optionally with its contact count", define: getCommand},
+	{name: "activities", summary: "Show an alias' forwards, replies and blocks, newest first", define: activitiesCommand},
+	{name: "export", summary: "Write every alias to an NDJSON file (resumable)", define: exportCommand},
+	{name: "delete", aliases: []string{"-d", "--delete"}, summary: "Delete an alias by email", define: deleteAliasCommand},
+	{name: "cleanup", summary: "Find (and optionally delete) old disabled aliases", define: cleanupCommand},
+	{name: "search", summary: "Search aliases by email, name or note", define: searchCommand},
+	{name: "index", summary: "Build the local alias index for search --local"},
+	{name: "index build", summary: "Fetch all aliases into the local index used by search --local", define: indexBuildCommand},
+	{name: "mailboxes", summary: "List mailboxes with their IDs", define: mailboxesCommand},
+	{name: "mailbox", summary: "Manage mailboxes"},
+	{name: "mailbox rm", aliases: []string{"mailbox delete"}, summary: "Delete a mailbox, transferring or deleting its aliases", define: mailboxRemoveCommand},
+	{name: "enable", summary: "Enable an alias", define: enableCommand},
+	{name: "disable", summary: "Disable an alias", define: disableCommand},
+	{name: "toggle", summary: "Flip an alias between enabled and disabled", define: toggleCommand},
+	{name: "update", summary: "Change an alias' note, name, tags, pinned or enabled state", define: updateCommand},
+	{name: "watch", summary: "Poll an alias and print counter changes until Ctrl-C", define: watchCommand},
+	{name: "open", summary: "Open an alias in the SimpleLogin web dashboard", define: openCommand},
+}
+
+// findCommand looks a command up by its name or one of its aliases.
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+		for _, a := range c.aliases {
+			if a == name {
+				return c, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// run parses args with the command's flags and runs it.
+func (c command) run(args []string, cfg config.SecureConfig) int {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	run := c.define(fs, cfg)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	return run()
+}
+
+// parseFlags parses args into fs, which must use flag.ContinueOnError. ok is
+// false when the command must not run and should exit with code: 0 after
+// -h, 2 for a bad flag. With --json the flag package's own message and usage
+// are replaced by an error envelope.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if global.json {
+		fs.SetOutput(io.Discard)
+	}
+	err := fs.Parse(args)
+	switch {
+	case err == nil:
+		return 0, true
+	case errors.Is(err, flag.ErrHelp):
+		return 0, false
+	case global.json:
+		return fail(2, err), false
+	}
+	return 2, false
+}
+
+// runGroup runs the subcommand of group named by args[0].
+func runGroup(group string, args []string, cfg config.SecureConfig) int {
+	if len(args) < 1 {
+		groupUsage(group)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "--help":
+		groupUsage(group)
+		return 0
+	}
+	c, ok := findCommand(group + " " + args[0])
+	if !ok {
+		code := failf(2, "Unknown %s command: %s", group, args[0])
+		if !global.json {
+			_, _ = fmt.Fprintln(os.Stderr)
+			groupUsage(group)
+		}
+		return code
+	}
+	return c.run(args[1:], cfg)
+}
+
+// printCommandSummaries prints a help line for each command directly in group,
+// or for each top-level command when group is "". Command groups get their
+// subcommands appended.
+func printCommandSummaries(group string) {
+	prefix := ""
+	if group != "" {
+		prefix = group + " "
+	}
+	for _, c := range commands {
+		sub, ok := strings.CutPrefix(c.name, prefix)
+		if !ok || strings.Contains(sub, " ") {
+			continue
+		}
+		summary := c.summary
+		if c.define == nil {
+			summary += " (" + c.name + " " + strings.Join(subcommands(c.name), "|") + ")"
+		}
+		_, _ = fmt.Printf("  %-11s %s\n", sub, summary)
+	}
+}
+
+// subcommands returns the names of group's subcommands.
+func subcommands(group string) []string {
+	var subs []string
+	for _, c := range commands {
+		if sub, ok := strings.CutPrefix(c.name, group+" "); ok {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func groupUsage(group string) {
+	_, _ = fmt.Println("Usage:")
+	_, _ = fmt.Printf("  simplelogin %s <command> [flags]\n", group)
+	_, _ = fmt.Println()
+	_, _ = fmt.Println("Commands:")
+	printCommandSummaries(group)
+}
+
+type flagSpec struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+type commandSpec struct {
+	Name  string     `json:"name"`
+	Flags []flagSpec `json:"flags"`
+}
+
+// describeCommand lists the flags c defines. Defaults come from an empty
+// config so no stored API key ends up in the output.
+func describeCommand(c command) commandSpec {
+	spec := commandSpec{Name: c.name, Flags: []flagSpec{}}
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	c.define(fs, config.SecureConfig{BaseConfig: config.Config{BaseURL: config.DefaultBaseURL}})
+	fs.VisitAll(func(f *flag.Flag) {
+		typ, usage := flag.UnquoteUsage(f)
+		switch v := f.Value.(type) {
+		case durationFlag:
+			typ = "duration"
+		case interface{ IsBoolFlag() bool }:
+			if v.IsBoolFlag() {
+				typ = "bool"
+			}
+		}
+		spec.Flags = append(spec.Flags, flagSpec{Name: f.Name, Type: typ, Default: f.DefValue, Usage: usage})
+	})
+	return spec
+}
+
+// runCommands lists the commands, or with --json every command with its flags,
+// for completion scripts and wrappers.
+func runCommands(args []string) int {
+	fs := flag.NewFlagSet("commands", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print every command with its flags (name, type, default, usage) as JSON")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	var specs []commandSpec
+	for _, c := range commands {
+		if c.define == nil {
+			continue
+		}
+		if !*asJSON {
+			_, _ = fmt.Println(c.name)
+			continue
+		}
+		specs = append(specs, describeCommand(c))
+	}
+	if !*asJSON {
+		return 0
+	}
+	if err := printJSON(specs); err != nil {
+		return fail(1, err)
+	}
+	return 0
+}
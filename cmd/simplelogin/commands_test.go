@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"simplelogincli/pkg/config"
+)
+
+func TestDescribeCommands(t *testing.T) {
+	for _, c := range commands {
+		if c.define == nil || c.name == "config path" {
+			continue
+		}
+		spec := describeCommand(c)
+		if len(spec.Flags) == 0 {
+			t.Errorf("%s: no flags described", spec.Name)
+		}
+		for _, f := range spec.Flags {
+			if f.Name == "api-key" && f.Default != "" {
+				t.Errorf("%s: api-key default %q leaks into the listing", spec.Name, f.Default)
+			}
+			if f.Type == "" || f.Type == "value" {
+				t.Errorf("%s --%s: type %q", spec.Name, f.Name, f.Type)
+			}
+		}
+	}
+}
+
+func TestCommandTable(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range commands {
+		for _, n := range append([]string{c.name}, c.aliases...) {
+			if seen[n] {
+				t.Errorf("%q is defined twice", n)
+			}
+			seen[n] = true
+		}
+		if group, _, ok := strings.Cut(c.name, " "); ok {
+			if g, found := findCommand(group); !found || g.define != nil {
+				t.Errorf("%s: %q is not a command group", c.name, group)
+			}
+		} else if c.define == nil && len(subcommands(c.name)) == 0 {
+			t.Errorf("command group %s has no subcommands", c.name)
+		}
+	}
+}
+
+func TestParseFlagsJSON(t *testing.T) {
+	saved := global.json
+	global.json = true
+	defer func() { global.json = saved }()
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+
+	fs := flag.NewFlagSet("random", flag.ContinueOnError)
+	fs.String("note", "", "")
+	if code, ok := parseFlags(fs, []string{"--nope"}); ok || code != 2 {
+		t.Fatalf("bad flag: code=%d ok=%v, want 2 false", code, ok)
+	}
+	if code, ok := parseFlags(fs, []string{"-h"}); ok || code != 0 {
+		t.Fatalf("-h: code=%d ok=%v, want 0 false", code, ok)
+	}
+	if code := dispatch("nope", nil, config.SecureConfig{}); code != 2 {
+		t.Fatalf("unknown command: code=%d, want 2", code)
+	}
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stderr = %q, want two JSON lines", b)
+	}
+	for _, line := range lines {
+		var env errorEnvelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil || env.Code != 2 || env.Error == "" {
+			t.Fatalf("stderr line %q: %+v, %v", line, env, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"simplelogincli/pkg/config"
+)
+
+func configPathCommand(fs *flag.FlagSet, _ config.SecureConfig) func() int {
+	return func() int {
+		p, err := config.Path()
+		if err != nil {
+			return fail(1, err)
+		}
+		_, _ = fmt.Println(p)
+		if _, err := os.Stat(p); err == nil {
+			infof(os.Stderr, "config file exists\n")
+		} else {
+			infof(os.Stderr, "config file does not exist yet\n")
+		}
+		return 0
+	}
+}
+
+func configEnvCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	includeKey := fs.Bool("include-key", false, "Also print SIMPLELOGIN_API_KEY (the secret key)")
+	return func() int {
+		_, _ = fmt.Printf("export SIMPLELOGIN_BASE_URL=%s\n", shellQuote(cfg.BaseConfig.BaseURL))
+		if *includeKey {
+			if cfg.APIKey == "" {
+				return fail(1, "no API key configured")
+			}
+			_, _ = fmt.Printf("export SIMPLELOGIN_API_KEY=%s\n", shellQuote(cfg.APIKey))
+		}
+		return 0
+	}
+}
+
+func configSetMailboxesCommand(fs *flag.FlagSet, _ config.SecureConfig) func() int {
+	clearIDs := fs.Bool("clear", false, "Remove the stored default mailboxes")
+	return func() int {
+		if *clearIDs != (fs.NArg() == 0) || fs.NArg() > 1 {
+			return fail(2, "usage: simplelogin config set-mailboxes <id,id,...> | --clear")
+		}
+		var ids []int
+		for _, p := range splitList(fs.Arg(0)) {
+			id, err := strconv.Atoi(p)
+			if err != nil || id <= 0 {
+				return failf(2, "invalid mailbox id: %q\n", p)
+			}
+			ids = append(ids, id)
+		}
+		if !*clearIDs && len(ids) == 0 {
+			return fail(2, "no mailbox ids given")
+		}
+		if err := config.SaveDefaultMailboxIDs(ids); err != nil {
+			return fail(1, "Failed to save config:", err)
+		}
+		if *clearIDs {
+			infof(os.Stderr, "Default mailboxes cleared.\n")
+		} else {
+			infof(os.Stderr, "Default mailboxes saved: %s\n", fs.Arg(0))
+		}
+		return 0
+	}
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// contactsListCommand prints every contact of an alias, or one page with --page,
+// as contact, reverse alias address and "blocked" when forwarding is blocked.
+func contactsListCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	page := fs.Int("page", -1, "Only fetch this page (starting at 0); all pages by default")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		var contacts []api.Contact
+		if *page >= 0 {
+			if contacts, err = c.ListContacts(ctx, a.ID, *page); err != nil {
+				return fail(1, err)
+			}
+			if len(contacts) == 0 && *page > 0 {
+				infof(os.Stderr, "page %d is empty (the alias may have fewer pages)\n", *page)
+				return exitNoData
+			}
+		} else if contacts, err = c.ListAllContacts(ctx, a.ID); err != nil {
+			return fail(1, err)
+		}
+		if len(contacts) == 0 {
+			infof(os.Stderr, "%s has no contacts\n", a.Email)
+			return 0
+		}
+		for _, ct := range contacts {
+			ra := ct.ReverseAliasAddress
+			if ra == "" {
+				ra = ct.ReverseAlias
+			}
+			blocked := ""
+			if ct.BlockForward {
+				blocked = "\tblocked"
+			}
+			_, _ = fmt.Printf("%s\t%s%s\n", ct.Contact, ra, blocked)
+		}
+		return 0
+	}
+}
+
+// contactsAddCommand creates a contact on an alias and prints its reverse alias
+// address: mail sent to it reaches the contact from the alias.
+func contactsAddCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	contact := fs.String("contact", "", "Email address to write to, optionally as \"Name <email>\" (required)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		if strings.TrimSpace(*contact) == "" {
+			return fail(2, "--contact is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		ct, err := c.CreateContact(ctx, a.ID, strings.TrimSpace(*contact))
+		if err != nil {
+			return fail(1, err)
+		}
+		if ct.Existed {
+			infof(os.Stderr, "%s already has contact %s\n", a.Email, ct.Contact)
+		}
+		ra := ct.ReverseAliasAddress
+		if ra == "" {
+			ra = ct.ReverseAlias
+		}
+		printValue(ra, false)
+		return 0
+	}
+}
+
+// exportedContact is one contact in contacts export output.
+type exportedContact struct {
+	Contact      string `json:"contact"`
+	ReverseAlias string `json:"reverse_alias"`
+	BlockForward bool   `json:"block_forward"`
+	Creation     string `json:"creation"`
+}
+
+func contactsExportCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	format := fs.String("format", "json", "Output format: json or csv")
+	output := fs.String("output", "-", "File to write, or - for stdout")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		if *format != "json" && *format != "csv" {
+			return failf(2, "unknown --format %q: want json or csv\n", *format)
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		contacts, err := c.ListAllContacts(ctx, a.ID)
+		if err != nil {
+			return fail(1, err)
+		}
+		var w io.Writer = os.Stdout
+		if *output != "-" {
+			f, err := os.OpenFile(*output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+			if err != nil {
+				return fail(1, err)
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+		}
+		if err := writeContacts(w, *format, contacts); err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stderr, "exported %d contacts of %s\n", len(contacts), a.Email)
+		return 0
+	}
+}
+
+func writeContacts(w io.Writer, format string, contacts []api.Contact) error {
+	rows := make([]exportedContact, 0, len(contacts))
+	for _, ct := range contacts {
+		ra := ct.ReverseAliasAddress
+		if ra == "" {
+			ra = ct.ReverseAlias
+		}
+		rows = append(rows, exportedContact{
+			Contact:      ct.Contact,
+			ReverseAlias: ra,
+			BlockForward: ct.BlockForward,
+			Creation:     time.Unix(ct.CreationTimestamp, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	if format == "json" {
+		return writeJSON(w, rows)
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"contact", "reverse_alias", "block_forward", "creation"})
+	for _, r := range rows {
+		_ = cw.Write([]string{r.Contact, r.ReverseAlias, strconv.FormatBool(r.BlockForward), r.Creation})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// parseDuration accepts Go durations extended with d (days) and w (weeks),
+// freely combined ("1w2d", "1d12h", "90d"), as well as ISO-8601 durations
+// without years or months ("P30D", "P1W", "PT1H30M").
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "P") {
+		return parseISODuration(s)
+	}
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	var total time.Duration
+	for rest := s; rest != ""; {
+		i := strings.IndexAny(rest, "dw")
+		if i < 0 {
+			d, err := time.ParseDuration(rest)
+			if err != nil {
+				return 0, err
+			}
+			return addDuration(total, d, s)
+		}
+		// the number right before d/w; anything earlier is a Go duration
+		j := i
+		for j > 0 && rest[j-1] >= '0' && rest[j-1] <= '9' {
+			j--
+		}
+		if j == i {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		if j > 0 {
+			d, err := time.ParseDuration(rest[:j])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			if total, err = addDuration(total, d, s); err != nil {
+				return 0, err
+			}
+		}
+		unit := day
+		if rest[i] == 'w' {
+			unit = week
+		}
+		var err error
+		if total, err = addUnits(total, rest[j:i], unit, s); err != nil {
+			return 0, err
+		}
+		rest = rest[i+1:]
+	}
+	return total, nil
+}
+
+// parseISODuration parses PnW, PnD and PnDTnHnMnS forms. Years and months
+// have no fixed length and are rejected.
+func parseISODuration(s string) (time.Duration, error) {
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T' && num == "" && !inTime:
+			inTime = true
+			continue
+		}
+		if num == "" {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+		}
+		count := num
+		num = ""
+		var unit time.Duration
+		switch {
+		case !inTime && r == 'W':
+			unit = week
+		case !inTime && r == 'D':
+			unit = day
+		case inTime && r == 'H':
+			unit = time.Hour
+		case inTime && r == 'M':
+			unit = time.Minute
+		case inTime && r == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q (years and months are not supported)", s)
+		}
+		var err error
+		if total, err = addUnits(total, count, unit, s); err != nil {
+			return 0, err
+		}
+	}
+	if num != "" || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+	}
+	return total, nil
+}
+
+// addUnits returns total plus num (decimal digits) times unit, or an error
+// naming s when the result does not fit in a time.Duration.
+func addUnits(total time.Duration, num string, unit time.Duration, s string) (time.Duration, error) {
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n > int64(math.MaxInt64/unit) {
+		return 0, fmt.Errorf("duration %q is out of range", s)
+	}
+	return addDuration(total, time.Duration(n)*unit, s)
+}
+
+// addDuration returns a+b, or an error naming s when the sum overflows.
+func addDuration(a, b time.Duration, s string) (time.Duration, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("duration %q is out of range", s)
+	}
+	return a + b, nil
+}
+
+// durationFlag is a flag.Value using parseDuration, for flags that should
+// accept days and weeks.
+type durationFlag struct{ d *time.Duration }
+
+func newDurationFlag(p *time.Duration, def time.Duration) durationFlag {
+	*p = def
+	return durationFlag{p}
+}
+
+func (f durationFlag) String() string {
+	if f.d == nil {
+		return ""
+	}
+	return f.d.String()
+}
+
+func (f durationFlag) Set(s string) error {
+	d, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*f.d = d
+	return nil
+}
+
+// relativeTime renders t relative to now, e.g. "5m ago", "yesterday" or
+// "3 months ago". Times in the future read as "just now".
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit + " ago"
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < day:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	case d < 2*day:
+		return "yesterday"
+	case d < 30*day:
+		return plural(int(d/day), "day")
+	case d < 365*day:
+		return plural(int(d/(30*day)), "month")
+	}
+	return plural(int(d/(365*day)), "year")
+}
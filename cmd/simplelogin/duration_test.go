@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{-time.Hour, "just now"},
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{2 * time.Hour, "2h ago"},
+		{30 * time.Hour, "yesterday"},
+		{day * 2, "2 days ago"},
+		{day * 45, "1 month ago"},
+		{day * 100, "3 months ago"},
+		{day * 800, "2 years ago"},
+	}
+	for _, tc := range cases {
+		if got := relativeTime(now.Add(-tc.ago), now); got != tc.want {
+			t.Errorf("relativeTime(-%s) = %q, want %q", tc.ago, got, tc.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"90m", 90 * time.Minute},
+		{"1d", day},
+		{"2w", 2 * week},
+		{"1w2d", week + 2*day},
+		{"1d12h", day + 12*time.Hour},
+		{"30m1d", 30*time.Minute + day},
+		{" 3d ", 3 * day},
+		{"P30D", 30 * day},
+		{"P1W", week},
+		{"PT1H30M", 90 * time.Minute},
+		{"P1DT2H3M4S", day + 2*time.Hour + 3*time.Minute + 4*time.Second},
+		{"106751d", 106751 * day},
+	}
+	for _, tc := range cases {
+		if got, err := parseDuration(tc.in); err != nil || got != tc.want {
+			t.Errorf("parseDuration(%q) = %s, %v; want %s", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseDurationRejects(t *testing.T) {
+	for _, in := range []string{
+		"", "d", "1x", "-1d", "1dd", "h1d",
+		"99999999999999999999d", "15251w", "106752d", "9223372036s1d",
+		"P", "PT", "P1Y", "P1M", "PT1D", "P1H", "P1D2", "P99999999999999999999D", "P15251W",
+	} {
+		if d, err := parseDuration(in); err == nil {
+			t.Errorf("parseDuration(%q) = %s, want an error", in, d)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// exportState is the sidecar file next to an export in progress. Offset is
+// the output size after the last complete page, so a resumed export can drop
+// a page that was only partly written. StartedAt is when the export first
+// began, recorded as its sync time once it completes.
+type exportState struct {
+	NextPage  int       `json:"next_page"`
+	Offset    int64     `json:"offset"`
+	Count     int       `json:"count"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// exportSync is the file recording when an export last completed, read by
+// export --since-last.
+type exportSync struct {
+	LastSync time.Time `json:"last_sync"`
+}
+
+// exportCommand writes every alias to a file as NDJSON, one page at a time, so an
+// interrupted export can be continued with --resume. --since-last appends
+// only the aliases created since the previous export instead.
+func exportCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	output := fs.String("output", "", "NDJSON file to write, one alias per line (required)")
+	resume := fs.Bool("resume", false, "Continue an interrupted export of --output from its state file")
+	sinceLast := fs.Bool("since-last", false, "Append only the aliases created since the last completed export of --output")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *output == "" || *output == "-" {
+			return fail(2, "--output is required: a resumable export needs a file")
+		}
+		if *resume && *sinceLast {
+			return fail(2, "--resume and --since-last are mutually exclusive")
+		}
+		syncPath := *output + ".sync"
+		statePath := *output + ".state"
+		st := exportState{StartedAt: time.Now().UTC()}
+		flags := os.O_CREATE | os.O_TRUNC | os.O_WRONLY
+		if *resume {
+			b, err := os.ReadFile(statePath)
+			if errors.Is(err, os.ErrNotExist) {
+				return failf(1, "nothing to resume: %s does not exist (the export may have finished)\n", statePath)
+			}
+			if err == nil {
+				err = json.Unmarshal(b, &st)
+			}
+			if err != nil {
+				return failf(1, "read %s: %v\n", statePath, err)
+			}
+			flags = os.O_WRONLY
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		if *sinceLast {
+			return exportSinceLast(c, *output, syncPath)
+		}
+		f, err := os.OpenFile(*output, flags, 0o600)
+		if err != nil {
+			return fail(1, err)
+		}
+		defer func() { _ = f.Close() }()
+		// drop anything written after the last page recorded in the state file
+		if err := f.Truncate(st.Offset); err != nil {
+			return fail(1, err)
+		}
+		if _, err := f.Seek(st.Offset, 0); err != nil {
+			return fail(1, err)
+		}
+		if *resume {
+			infof(os.Stderr, "resuming at page %d (%d aliases already exported)\n", st.NextPage, st.Count)
+		} else if err := writeJSONFile(statePath, st); err != nil {
+			return fail(1, err)
+		}
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			res, err := c.ListAliases(ctx, st.NextPage, "")
+			cancel()
+			if err != nil {
+				return failf(1, "page %d: %v; %d aliases kept in %s; run again with --resume to continue", st.NextPage, err, st.Count, *output)
+			}
+			if len(res.Aliases) == 0 {
+				break
+			}
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			for _, a := range res.Aliases {
+				if err := enc.Encode(a); err != nil {
+					return fail(1, err)
+				}
+			}
+			n, err := f.Write(buf.Bytes())
+			if err == nil {
+				err = f.Sync()
+			}
+			if err != nil {
+				return fail(1, err)
+			}
+			st.NextPage++
+			st.Offset += int64(n)
+			st.Count += len(res.Aliases)
+			if err := writeJSONFile(statePath, st); err != nil {
+				return fail(1, err)
+			}
+			verbosef("page %d: %d aliases\n", st.NextPage-1, len(res.Aliases))
+		}
+		if err := f.Close(); err != nil {
+			return fail(1, err)
+		}
+		_ = os.Remove(statePath)
+		if err := writeJSONFile(syncPath, exportSync{LastSync: st.StartedAt}); err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stderr, "exported %d aliases to %s\n", st.Count, *output)
+		return 0
+	}
+}
+
+// exportSinceLast appends the aliases created since the time recorded in
+// syncPath to output and moves the recorded time forward. Aliases created
+// while the previous export ran may be written twice.
+func exportSinceLast(c *api.Client, output, syncPath string) int {
+	b, err := os.ReadFile(syncPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return failf(1, "no completed export of %s to continue from; run export without --since-last first\n", output)
+	}
+	var sync exportSync
+	if err == nil {
+		err = json.Unmarshal(b, &sync)
+	}
+	if err != nil {
+		return failf(1, "read %s: %v\n", syncPath, err)
+	}
+	started := time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	aliases, err := c.AliasesModifiedSince(ctx, sync.LastSync)
+	if err != nil {
+		return fail(1, err)
+	}
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fail(1, err)
+	}
+	defer func() { _ = f.Close() }()
+	enc := json.NewEncoder(f)
+	for _, a := range aliases {
+		if err := enc.Encode(a); err != nil {
+			return fail(1, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fail(1, err)
+	}
+	if err := writeJSONFile(syncPath, exportSync{LastSync: started}); err != nil {
+		return fail(1, err)
+	}
+	infof(os.Stderr, "appended %d aliases created since %s to %s\n", len(aliases), sync.LastSync.Format(time.RFC3339), output)
+	return 0
+}
+
+// writeJSONFile replaces path with v as JSON atomically, so an interruption
+// never leaves a half-written state file behind.
+func writeJSONFile(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// getCommand prints one alias as a key/value block.
+func getCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	withContacts := fs.Bool("with-contacts", false, "Also count the alias' contacts (reverse aliases); pages through all of them")
+	relative := fs.Bool("relative-time", false, "Show the creation time as in \"3 days ago\" instead of a timestamp")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		contacts := -1
+		if *withContacts {
+			all, err := c.ListAllContacts(ctx, a.ID)
+			if err != nil {
+				return fail(1, "count contacts:", err)
+			}
+			contacts = len(all)
+		}
+		printAliasDetails(a, contacts, *relative)
+		return 0
+	}
+}
+
+// printAliasDetails prints every alias field on its own line; contacts is
+// left out when negative.
+func printAliasDetails(a api.Alias, contacts int, relative bool) {
+	field := func(k string, v any) { _, _ = fmt.Printf("%-10s %v\n", k+":", v) }
+	field("id", a.ID)
+	field("email", a.Email)
+	if a.Name != nil {
+		field("name", *a.Name)
+	}
+	field("enabled", a.Enabled)
+	field("pinned", a.Pinned)
+	created := time.Unix(a.CreationTimestamp, 0)
+	if relative {
+		field("created", relativeTime(created, time.Now()))
+	} else {
+		field("created", created.UTC().Format(time.RFC3339))
+	}
+	field("forwards", a.NbForward)
+	field("blocks", a.NbBlock)
+	field("replies", a.NbReply)
+	if contacts >= 0 {
+		field("contacts", contacts)
+	}
+	var mailboxes []string
+	for _, mb := range a.Mailboxes {
+		mailboxes = append(mailboxes, mb.Email)
+	}
+	if len(mailboxes) > 0 {
+		field("mailboxes", strings.Join(mailboxes, ", "))
+	}
+	if a.Note != nil && *a.Note != "" {
+		field("note", *a.Note)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/config"
+	"simplelogincli/pkg/importer"
+)
+
+// importResult is one line of import --json-stream output.
+type importResult struct {
+	Input  string `json:"input"`
+	Email  string `json:"email,omitempty"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func importCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	format := fs.String("format", "", "Export format: bitwarden (JSON or CSV) or 1password (CSV)")
+	file := fs.String("file", "-", "Export file to read, or - for stdin")
+	dryRun := fs.Bool("dry-run", false, "Only list what would be imported")
+	jsonStream := fs.Bool("json-stream", false, "Write one JSON result object per item to stdout as it is processed")
+	metricsFile := fs.String("metrics-file", "", "Write Prometheus textfile-collector metrics for this run to this file")
+	retryFile := fs.String("retry-file", "", "Write the entries that failed to this file, in the input's format with each error noted, to import again")
+	var pace pacer
+	fs.Var(newDurationFlag(&pace.interval, 0), "min-interval", "Wait at least this long between consecutive creates, e.g. 2s")
+	return func() int {
+		start := time.Now()
+		if *format == "" {
+			return fail(2, "--format is required (bitwarden or 1password)")
+		}
+		if *apiKey == "" && !*dryRun {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		var in io.Reader = os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return fail(1, err)
+			}
+			defer func() { _ = f.Close() }()
+			in = f
+		}
+		entries, skipped, err := importer.Parse(*format, in)
+		if err != nil {
+			return fail(2, err)
+		}
+		stream := json.NewEncoder(os.Stdout)
+		for _, s := range skipped {
+			if *jsonStream {
+				_ = stream.Encode(importResult{Input: s.Item, Status: "skipped", Error: s.Reason})
+				continue
+			}
+			infof(os.Stderr, "skipped %s: %s\n", s.Item, s.Reason)
+		}
+		if *dryRun {
+			for _, e := range entries {
+				_, _ = fmt.Printf("%s\t%s\n", e.Email, e.Site)
+			}
+			infof(os.Stderr, "%d to import, %d skipped\n", len(entries), len(skipped))
+			return 0
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		var failed []importer.Failed
+		for _, e := range entries {
+			note := fmt.Sprintf("%s (imported, was %s)", e.Site, e.Email)
+			if err := pace.wait(context.Background()); err != nil {
+				return fail(1, err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			a, err := c.CreateRandomAlias(ctx, "", "", &note, nil)
+			cancel()
+			if err != nil {
+				failed = append(failed, importer.Failed{Entry: e, Err: err.Error()})
+				if *jsonStream {
+					_ = stream.Encode(importResult{Input: e.Email, Status: "failed", Error: err.Error()})
+					continue
+				}
+				failf(1, "%s: %v", e.Email, err)
+				continue
+			}
+			if *jsonStream {
+				_ = stream.Encode(importResult{Input: e.Email, Email: a.Email, ID: a.ID, Status: "created"})
+				continue
+			}
+			_, _ = fmt.Printf("%s\t%s\n", e.Email, a.Email)
+		}
+		infof(os.Stderr, "imported %d, skipped %d, failed %d\n", len(entries)-len(failed), len(skipped), len(failed))
+		saveMetrics(*metricsFile, "import", bulkMetrics{created: len(entries) - len(failed), skipped: len(skipped), failed: len(failed), duration: time.Since(start)})
+		if len(failed) == 0 {
+			return 0
+		}
+		if *retryFile != "" {
+			if err := saveRetryFile(*retryFile, failed); err != nil {
+				fail(1, "write retry file:", err)
+			} else {
+				infof(os.Stderr, "failed entries written to %s; re-run with: import --format %s --file %s\n", *retryFile, *format, *retryFile)
+			}
+		}
+		return 1
+	}
+}
+
+// saveRetryFile writes the failed entries in the format they were read in, for
+// a later import of just those.
+func saveRetryFile(path string, failed []importer.Failed) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := importer.WriteRetryFile(f, failed); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+func indexBuildCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		idx, err := rebuildIndex(c, *baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		path, _ := config.IndexPath()
+		infof(os.Stderr, "indexed %d aliases in %s\n", len(idx.Aliases), path)
+		return 0
+	}
+}
+
+// rebuildIndex fetches every alias and stores them as the local index.
+func rebuildIndex(c *api.Client, baseURL, apiKey string) (config.AliasIndex, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	idx := config.AliasIndex{
+		BuiltAt: time.Now().UTC(),
+		BaseURL: baseURL,
+		Account: config.KeyFingerprint(apiKey),
+		Aliases: []config.IndexedAlias{},
+	}
+	aliases, err := c.ListAllAliases(ctx, "")
+	if err != nil {
+		return idx, err
+	}
+	for _, a := range aliases {
+		ia := config.IndexedAlias{ID: a.ID, Email: a.Email, Enabled: a.Enabled}
+		if a.Name != nil {
+			ia.Name = *a.Name
+		}
+		if a.Note != nil {
+			ia.Note = *a.Note
+		}
+		idx.Aliases = append(idx.Aliases, ia)
+	}
+	if err := config.SaveIndex(idx); err != nil {
+		return idx, fmt.Errorf("save index: %w", err)
+	}
+	return idx, nil
+}
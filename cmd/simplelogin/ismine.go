@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// isMineCommand reports whether an address is one of the account's aliases.
+func isMineCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	email := fs.String("email", "", "Address to check (required)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		addr := strings.TrimSpace(*email)
+		if addr == "" {
+			return fail(2, "--email is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		a, err := c.FindAliasByEmail(ctx, addr)
+		if errors.Is(err, api.ErrAliasNotFound) {
+			_, _ = fmt.Println("no")
+			return exitNotMine
+		}
+		if err != nil {
+			return fail(1, err)
+		}
+		state := "disabled"
+		if a.Enabled {
+			state = "enabled"
+		}
+		_, _ = fmt.Printf("yes\tid=%d\t%s\n", a.ID, state)
+		return 0
+	}
+}
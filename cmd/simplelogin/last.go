@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// rememberAlias records a as the last created alias. Failures are only warned
+// about since the alias itself was created.
+func rememberAlias(a api.Alias) {
+	err := config.SaveLastAlias(config.LastAlias{Email: a.Email, ID: a.ID, CreatedAt: time.Now()})
+	if err != nil {
+		infof(os.Stderr, "warning: could not remember alias: %v\n", err)
+	}
+}
+
+func lastCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	clear := fs.Bool("clear", false, "Forget the remembered alias")
+	return func() int {
+		if *clear {
+			if err := config.ClearLastAlias(); err != nil {
+				return fail(1, err)
+			}
+			infof(os.Stdout, "Last alias cleared.\n")
+			return 0
+		}
+		a, ok, err := config.LoadLastAlias()
+		if err != nil {
+			return fail(1, err)
+		}
+		if !ok {
+			infof(os.Stderr, "no alias remembered; create one with --remember or set remember_last in the config\n")
+			return exitNoData
+		}
+		_, _ = fmt.Printf("%s\tid=%d\tcreated=%s\n", a.Email, a.ID, a.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		return 0
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+func listCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	page := fs.Int("page", 0, "Page ID to fetch (starting at 0)")
+	hostname := fs.String("hostname", "", "Only aliases suggested for this website hostname")
+	mailbox := fs.String("mailbox", "", "Only aliases forwarding to this mailbox (id or email); scans all pages")
+	newestFirst := fs.Bool("newest-first", false, "Fetch all pages and sort by creation time, newest first")
+	oldestFirst := fs.Bool("oldest-first", false, "Fetch all pages and sort by creation time, oldest first")
+	outputTemplate := fs.String("output-template", "", "Go text/template applied to each alias instead of the default line")
+	templateFile := fs.String("template-file", "", "Read the --output-template from this file")
+	relative := fs.Bool("relative-time", false, "Add each alias' creation time, as in \"3 days ago\"")
+	tags := tagsFlag{}
+	fs.Var(tags, "tag", "Only aliases whose note has this `key=value` tag; repeatable, scans all pages")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *page < 0 {
+			return fail(2, "--page must be >= 0")
+		}
+		if *newestFirst && *oldestFirst {
+			return fail(2, "--newest-first and --oldest-first are mutually exclusive")
+		}
+		scanAll := *mailbox != "" || len(tags) > 0 || *newestFirst || *oldestFirst
+		pageSet := false
+		fs.Visit(func(f *flag.Flag) { pageSet = pageSet || f.Name == "page" })
+		if scanAll && pageSet {
+			return fail(2, "--page cannot be combined with --mailbox, --tag, --newest-first or --oldest-first, which scan all pages")
+		}
+		tmpl, err := loadTemplate(*outputTemplate, *templateFile)
+		if err != nil {
+			return fail(2, err)
+		}
+		if tmpl != nil && global.json {
+			return fail(2, "--json and --output-template/--template-file are mutually exclusive")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		if scanAll {
+			order := orderNone
+			if *newestFirst {
+				order = orderNewestFirst
+			} else if *oldestFirst {
+				order = orderOldestFirst
+			}
+			return listAllPages(c, *hostname, *mailbox, tags, order, tmpl, *relative)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		res, err := c.ListAliases(ctx, *page, *hostname)
+		if err != nil {
+			return fail(1, err)
+		}
+		if code := printAliases(res.Aliases, tmpl, *relative); code != 0 {
+			return code
+		}
+		if len(res.Aliases) == 0 {
+			if *page > 0 {
+				infof(os.Stderr, "page %d is empty (account may have fewer pages)\n", *page)
+				return exitNoData
+			}
+			infof(os.Stderr, "no aliases found\n")
+		}
+		return 0
+	}
+}
+
+type aliasOrder int
+
+const (
+	orderNone aliasOrder = iota // as returned by the server
+	orderNewestFirst
+	orderOldestFirst
+)
+
+// listAllPages collects every page before printing, optionally keeping only
+// the aliases that forward to mailbox (id or email) and carry tags, and
+// sorting by creation time.
+func listAllPages(c *api.Client, hostname, mailbox string, tags tagsFlag, order aliasOrder, tmpl *template.Template, relative bool) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	id, err := strconv.Atoi(mailbox)
+	if mailbox != "" && err != nil {
+		res, err := c.Mailboxes(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		mb, ok := findMailbox(res.Mailboxes, 0, mailbox)
+		if !ok {
+			return failf(1, "mailbox %s not found\n", mailbox)
+		}
+		id = mb.ID
+	}
+	aliases, err := c.ListAllAliases(ctx, hostname)
+	if err != nil {
+		return fail(1, err)
+	}
+	var all []api.Alias
+	for _, a := range aliases {
+		if (mailbox == "" || a.HasMailbox(id)) && hasTags(a, tags) {
+			all = append(all, a)
+		}
+	}
+	switch order {
+	case orderNewestFirst:
+		sort.SliceStable(all, func(i, j int) bool { return all[i].CreationTimestamp > all[j].CreationTimestamp })
+	case orderOldestFirst:
+		sort.SliceStable(all, func(i, j int) bool { return all[i].CreationTimestamp < all[j].CreationTimestamp })
+	}
+	if code := printAliases(all, tmpl, relative); code != 0 {
+		return code
+	}
+	if len(all) == 0 {
+		if mailbox != "" {
+			infof(os.Stderr, "no aliases forward to mailbox %s\n", mailbox)
+		} else if len(tags) > 0 {
+			infof(os.Stderr, "no aliases are tagged %s\n", tags)
+		} else {
+			infof(os.Stderr, "no aliases found\n")
+		}
+	}
+	return 0
+}
+
+// printAliases prints each alias with tmpl, or as printAliasLine when tmpl is
+// nil. With --json it prints the aliases as a JSON array instead.
+func printAliases(aliases []api.Alias, tmpl *template.Template, relative bool) int {
+	if global.json {
+		if aliases == nil {
+			aliases = []api.Alias{}
+		}
+		if err := printJSON(aliases); err != nil {
+			return fail(1, err)
+		}
+		return 0
+	}
+	for _, a := range aliases {
+		if tmpl == nil {
+			printAliasLine(a, relative)
+			continue
+		}
+		if err := tmpl.Execute(os.Stdout, a); err != nil {
+			return fail(1, "template:", err)
+		}
+	}
+	return 0
+}
+
+// printAliasLine prints the one-line alias summary used by list and search,
+// with the creation time as a relative "created=" column when relative is set.
+func printAliasLine(a api.Alias, relative bool) {
+	state := "disabled"
+	if a.Enabled {
+		state = "enabled"
+	}
+	created := ""
+	if relative {
+		created = "\tcreated=" + relativeTime(time.Unix(a.CreationTimestamp, 0), time.Now())
+	}
+	_, _ = fmt.Printf("%s\tid=%d\t%s\tdomain=%s%s\n", a.Email, a.ID, state, a.Domain(), created)
+}
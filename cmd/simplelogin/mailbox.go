@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// mailboxesCommand prints the account's mailboxes with the ids that custom
+// --mailbox-ids and the mailbox commands take.
+func mailboxesCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	asJSON := fs.Bool("json", false, "Print the mailboxes as JSON (like the global --json, but errors stay plain text)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		res, err := c.Mailboxes(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		if global.json || *asJSON {
+			if err := printJSON(res.Mailboxes); err != nil {
+				return fail(1, err)
+			}
+			return 0
+		}
+		for _, mb := range res.Mailboxes {
+			flags := []string{"unverified"}
+			if mb.Verified {
+				flags[0] = "verified"
+			}
+			if mb.Default {
+				flags = append(flags, "default")
+			}
+			_, _ = fmt.Printf("id=%d\t%s\t%s\taliases=%d\n", mb.ID, mb.Email, strings.Join(flags, ","), mb.NbAlias)
+		}
+		return 0
+	}
+}
+
+// findMailbox returns the mailbox with the given id, or with the given email when id is 0.
+func findMailbox(mailboxes []api.Mailbox, id int, email string) (api.Mailbox, bool) {
+	for _, mb := range mailboxes {
+		if (id > 0 && mb.ID == id) || (id == 0 && mb.Email == email) {
+			return mb, true
+		}
+	}
+	return api.Mailbox{}, false
+}
+
+// checkMailboxIDs fails with the offending ids when any of ids is not one of
+// the account's mailboxes.
+func checkMailboxIDs(ctx context.Context, c *api.Client, ids []int) error {
+	res, err := c.Mailboxes(ctx)
+	if err != nil {
+		return fmt.Errorf("validate mailbox ids: %w", err)
+	}
+	var unknown, known []string
+	for _, id := range ids {
+		if _, ok := findMailbox(res.Mailboxes, id, ""); !ok {
+			unknown = append(unknown, strconv.Itoa(id))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	for _, mb := range res.Mailboxes {
+		known = append(known, fmt.Sprintf("%d (%s)", mb.ID, mb.Email))
+	}
+	return fmt.Errorf("unknown mailbox id(s): %s; your mailboxes: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+func mailboxRemoveCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	id := fs.Int("id", 0, "ID of the mailbox to delete")
+	email := fs.String("email", "", "Email of the mailbox to delete (instead of --id)")
+	transferTo := fs.Int("transfer-to", 0, "Mailbox ID that takes over the aliases of the deleted mailbox")
+	force := fs.Bool("force", false, "Delete the mailbox's aliases along with it")
+	yes := fs.Bool("yes", false, "Skip the confirmation prompt")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *id <= 0 && *email == "" {
+			return fail(2, "--id or --email is required")
+		}
+		if *transferTo > 0 && *force {
+			return fail(2, "--transfer-to and --force are mutually exclusive")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		res, err := c.Mailboxes(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		mb, ok := findMailbox(res.Mailboxes, *id, *email)
+		if !ok {
+			return fail(1, "mailbox not found")
+		}
+		if mb.Default {
+			return failf(1, "%s is the default mailbox and cannot be deleted; make another mailbox the default first\n", mb.Email)
+		}
+		target := api.DeleteMailboxAliases
+		action := "its aliases will be deleted"
+		switch {
+		case *transferTo > 0:
+			to, ok := findMailbox(res.Mailboxes, *transferTo, "")
+			if !ok || to.ID == mb.ID {
+				return failf(2, "--transfer-to %d is not another mailbox in this account\n", *transferTo)
+			}
+			target = to.ID
+			action = "its aliases will move to " + to.Email
+		case mb.NbAlias > 0 && !*force:
+			msg := fmt.Sprintf("%s owns %d aliases. Pass --transfer-to <mailbox-id> to move them to another mailbox, or --force to delete them.", mb.Email, mb.NbAlias)
+			for _, other := range res.Mailboxes {
+				if other.ID != mb.ID {
+					msg += fmt.Sprintf("\n  %d\t%s", other.ID, other.Email)
+				}
+			}
+			return fail(2, msg)
+		}
+		if !*yes && !confirm(fmt.Sprintf("Delete mailbox %s (%d aliases; %s)?", mb.Email, mb.NbAlias, action)) {
+			return fail(1, "aborted")
+		}
+		if err := c.DeleteMailbox(ctx, mb.ID, target); err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stdout, "Mailbox deleted: %s\n", mb.Email)
+		return 0
+	}
+}
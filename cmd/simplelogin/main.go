@@ -3,8 +3,13 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"sort"
 	"strconv"
@@ -15,44 +20,181 @@ import (
 	"simplelogincli/pkg/config"
 )
 
+// Exit codes beyond 0 (success), 1 (error) and 2 (usage). Each has a single
+// meaning; usage lists them.
+const (
+	exitNoData         = 3 // the request succeeded but returned nothing, e.g. a page past the end
+	exitSuffixNotFound = 4 // custom --suffix is not among the account's alias options
+	exitRateLimited    = 5 // the server kept answering 429 Too Many Requests after every retry
+	exitNotMine        = 6 // is-mine: the address is not an alias on the account
+)
+
 func main() {
-	cfg, err := config.Load()
+	// Global flags come first so that --json also covers a broken config.
+	rest, err := parseGlobalFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		os.Exit(2)
+	}
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Failed to load config:", err)
-		os.Exit(1)
+		os.Exit(fail(2, err))
 	}
-
-	if len(os.Args) < 2 {
+	if len(rest) < 1 {
 		usage()
 		os.Exit(2)
 	}
 
-	cmd := os.Args[1]
-	args := os.Args[2:]
+	cfg, err := config.Load()
+	if err != nil {
+		os.Exit(fail(1, "Failed to load config:", err))
+	}
+
+	code := dispatch(rest[0], rest[1:], cfg)
+	if global.timings {
+		printTimings()
+	}
+	os.Exit(code)
+}
 
-	var code int
-	switch cmd {
-	case "set-key":
-		code = runSetKey(args, cfg)
-	case "whoami":
-		code = runWhoAmI(args, cfg)
-	case "options":
-		code = runOptions(args, cfg)
-	case "random":
-		code = runRandom(args, cfg)
-	case "custom":
-		code = runCustom(args, cfg)
+// dispatch runs the named command and returns its exit code.
+func dispatch(name string, args []string, cfg config.SecureConfig) int {
+	switch name {
 	case "help", "-h", "--help":
 		usage()
-		code = 0
-	case "delete", "-d", "--delete":
-		code = runDeleteAlias(args, cfg)
-	default:
-		_, _ = fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", cmd)
-		usage()
-		code = 2
+		return 0
+	case "commands":
+		return runCommands(args)
+	}
+	c, ok := findCommand(name)
+	if !ok {
+		code := failf(2, "Unknown command: %s", name)
+		if !global.json {
+			_, _ = fmt.Fprintln(os.Stderr)
+			usage()
+		}
+		return code
+	}
+	if c.define == nil {
+		return runGroup(c.name, args, cfg)
+	}
+	return c.run(args, cfg)
+}
+
+// globalFlags holds the flags accepted before the command name.
+type globalFlags struct {
+	compact    bool
+	indent     string
+	quiet      bool
+	verbose    bool
+	clientCert string
+	clientKey  string
+	timings    bool
+	retries    int
+	retryOn    []int // nil: the client default (429 and 5xx)
+	authHeader string
+	bearer     bool
+	readOnly   bool
+	strict     bool
+	json       bool
+}
+
+var global = globalFlags{indent: "2", retries: api.DefaultRetries}
+
+// timings collects request durations across every client when --timings is set.
+var timings api.Timings
+
+// commandAliases are dash-prefixed arguments that name a command rather than a global flag.
+var commandAliases = map[string]bool{"-h": true, "--help": true, "-d": true, "--delete": true}
+
+// parseGlobalFlags consumes the global flags in front of the command name and
+// returns the remaining arguments, starting with the command.
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("simplelogin", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // the caller reports the error, as JSON with --json
+	fs.BoolVar(&global.compact, "compact", global.compact, "Emit JSON on a single line")
+	fs.StringVar(&global.indent, "indent", global.indent, "JSON indentation: number of spaces or 'tab'")
+	fs.BoolVar(&global.quiet, "quiet", global.quiet, "Suppress informational messages; only data and errors are printed")
+	fs.BoolVar(&global.verbose, "verbose", global.verbose, "Explain decisions such as defaults picked from account settings")
+	fs.StringVar(&global.clientCert, "client-cert", global.clientCert, "PEM client certificate for mTLS")
+	fs.StringVar(&global.clientKey, "client-key", global.clientKey, "PEM private key for --client-cert")
+	fs.BoolVar(&global.timings, "timings", global.timings, "Print per-endpoint request timings to stderr when done")
+	fs.StringVar(&global.authHeader, "auth-header", global.authHeader, "Header carrying the API key (default Authentication, or Authorization with --bearer)")
+	fs.BoolVar(&global.bearer, "bearer", global.bearer, "Send the API key as 'Bearer <key>'")
+	global.readOnly, _ = strconv.ParseBool(os.Getenv("SIMPLELOGIN_READONLY"))
+	fs.BoolVar(&global.readOnly, "read-only", global.readOnly, "Refuse every request that would create, change or delete anything (or SIMPLELOGIN_READONLY=1)")
+	fs.BoolVar(&global.strict, "strict-errors", global.strict, "Treat successful responses with an \"error\" field as failures (misconfigured self-hosted servers)")
+	fs.BoolVar(&global.json, "json", global.json, "Print results as JSON, and errors on stderr as {\"error\":...,\"code\":N,\"status\":N}")
+	fs.IntVar(&global.retries, "retries", global.retries, "How many times to retry a request that got a transient error status or could not connect")
+	fs.Func("retry-on", "Comma-separated HTTP status codes to retry (default 429 and all 5xx)", func(v string) error {
+		codes, err := api.ParseStatusCodes(v)
+		global.retryOn = codes
+		return err
+	})
+	n := 0
+	for n < len(args) && strings.HasPrefix(args[n], "-") && !commandAliases[args[n]] {
+		name := strings.TrimLeft(args[n], "-")
+		n++
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil {
+			if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !bf.IsBoolFlag() {
+				n++
+			}
+		}
+	}
+	n = min(n, len(args))
+	if err := fs.Parse(args[:n]); err != nil {
+		return nil, err
+	}
+	if _, err := jsonIndent(); err != nil {
+		return nil, err
+	}
+	if global.retries < 0 {
+		return nil, errors.New("--retries must be >= 0")
+	}
+	if (global.clientCert == "") != (global.clientKey == "") {
+		return nil, errors.New("--client-cert and --client-key must be given together")
+	}
+	return args[n:], nil
+}
+
+// newClient builds an API client and applies the global connection flags.
+func newClient(baseURL, apiKey string) (*api.Client, error) {
+	c := api.NewClient(baseURL, apiKey)
+	if global.clientCert != "" {
+		if err := c.WithClientCertificate(global.clientCert, global.clientKey); err != nil {
+			return nil, err
+		}
+	}
+	if global.timings {
+		c.WithTimings(&timings)
+	}
+	c.WithAuthHeader(global.authHeader)
+	c.WithBearerAuth(global.bearer)
+	c.WithReadOnly(global.readOnly)
+	c.WithStrictErrorDetection(global.strict)
+	c.SetRetryPolicy(global.retries, api.DefaultRetryBackoff)
+	if global.retryOn != nil {
+		if err := c.WithRetryStatusCodes(global.retryOn...); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// printTimings writes the --timings summary to stderr.
+func printTimings() {
+	summary := timings.Summary()
+	if len(summary) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "timings: no API requests made")
+		return
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "timings:")
+	for _, e := range summary {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-40s calls=%d total=%s avg=%s\n", e.Endpoint, e.Calls,
+			e.Total.Round(time.Millisecond), e.Average().Round(time.Millisecond))
 	}
-	os.Exit(code)
 }
 
 func usage() {
@@ -62,262 +204,732 @@ func usage() {
 	_, _ = fmt.Println("  simplelogin <command> [flags]")
 	_, _ = fmt.Println()
 	_, _ = fmt.Println("Commands:")
-	_, _ = fmt.Println("  set-key     Store API key and base URL")
-	_, _ = fmt.Println("  whoami      Show account info for the current API key")
-	_, _ = fmt.Println("  options     List available alias suffix options")
-	_, _ = fmt.Println("  random      Create a random alias")
-	_, _ = fmt.Println("  custom      Create a custom alias from prefix + suffix")
+	printCommandSummaries("")
+	_, _ = fmt.Println("  commands    List the commands (--json: with their flags, for completion scripts)")
+	_, _ = fmt.Println()
+	_, _ = fmt.Println("Global flags (before the command):")
+	_, _ = fmt.Println("  --compact   Emit JSON output on a single line")
+	_, _ = fmt.Println("  --indent N  Indent JSON output with N spaces or 'tab' (default 2)")
+	_, _ = fmt.Println("  --quiet     Only print data and errors, no status messages")
+	_, _ = fmt.Println("  --verbose   Explain decisions such as defaults taken from account settings")
+	_, _ = fmt.Println("  --timings   Print per-endpoint request timings to stderr")
+	_, _ = fmt.Println("  --retries N Retry transient failures N times (default 3)")
+	_, _ = fmt.Println("  --retry-on CODES  Status codes to retry, e.g. 429,502,503,504 (default 429 and 5xx)")
+	_, _ = fmt.Println("  --auth-header NAME, --bearer  How to send the API key (for proxies)")
+	_, _ = fmt.Println("  --read-only Refuse requests that create, change or delete anything")
+	_, _ = fmt.Println("  --strict-errors  Treat 200 responses with an \"error\" field as failures")
+	_, _ = fmt.Println("  --json      Print results as JSON on stdout and errors as JSON on stderr")
+	_, _ = fmt.Println("  --client-cert FILE, --client-key FILE")
+	_, _ = fmt.Println("              Present a TLS client certificate (mTLS)")
 	_, _ = fmt.Println()
 	_, _ = fmt.Println("Global env vars:")
 	_, _ = fmt.Println("  SIMPLELOGIN_API_KEY   API key (overrides stored key)")
 	_, _ = fmt.Println("  SIMPLELOGIN_BASE_URL  Base URL (default:", config.DefaultBaseURL, ")")
 	_, _ = fmt.Println()
+	_, _ = fmt.Println("Exit codes:")
+	_, _ = fmt.Println("  0 success, 1 error, 2 bad usage")
+	_, _ = fmt.Printf("  %d no data (e.g. a page past the end)\n", exitNoData)
+	_, _ = fmt.Printf("  %d suffix not among the alias options (custom, provision)\n", exitSuffixNotFound)
+	_, _ = fmt.Printf("  %d still rate limited after every retry\n", exitRateLimited)
+	_, _ = fmt.Printf("  %d not one of your aliases (is-mine)\n", exitNotMine)
+	_, _ = fmt.Println()
 	_, _ = fmt.Println("Run 'simplelogin <command> -h' for command-specific flags.")
 }
 
-func runSetKey(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("set-key", flag.ExitOnError)
+func setKeyCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
 	key := fs.String("api-key", "", "API key to store (or use SIMPLELOGIN_API_KEY env)")
-	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
-	_ = fs.Parse(args)
-	if *key == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "--api-key is required (or set SIMPLELOGIN_API_KEY)")
-		return 2
-	}
-	cfg.APIKey = *key
-	cfg.BaseConfig.BaseURL = *baseURL
-	if err := config.Save(cfg); err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Failed to save config:", err)
-		return 1
-	}
-	_, _ = fmt.Println("API key saved.")
-	return 0
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL, or a comma-separated list tried in order on connection failure")
+	validate := fs.Bool("validate", false, "Check the key with a user_info call and only store it if it works")
+	dryRun := fs.Bool("dry-run", false, "With --validate, check the key but do not store it")
+	force := fs.Bool("force", false, "Replace a stored key even if it belongs to a different account")
+	return func() int {
+		if *key == "" {
+			return fail(2, "--api-key is required (or set SIMPLELOGIN_API_KEY)")
+		}
+		if *dryRun && !*validate {
+			return fail(2, "--dry-run requires --validate")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		replacing := cfg.APIKey != "" && cfg.APIKey != *key && !*force
+		var newEmail string
+		if *validate || replacing {
+			var err error
+			newEmail, err = accountEmail(ctx, *baseURL, *key)
+			if err != nil {
+				if *validate {
+					return fail(1, "API key check failed, not saving:", err)
+				}
+				return failf(1, "cannot check which account the new key belongs to (%v); use --force to save it anyway", err)
+			}
+		}
+		if *validate {
+			infof(os.Stdout, "API key is valid for %s.\n", newEmail)
+			if *dryRun {
+				return 0
+			}
+		}
+		if replacing {
+			// a stored key that no longer works has no account left to protect
+			oldEmail, err := accountEmail(ctx, cfg.BaseConfig.BaseURL, cfg.APIKey)
+			if err != nil {
+				verbosef("stored key could not be checked (%v); replacing it\n", err)
+			} else if !strings.EqualFold(oldEmail, newEmail) {
+				question := fmt.Sprintf("The stored key belongs to %s, the new one to %s. Replace it?", oldEmail, newEmail)
+				if !stdinIsTerminal() {
+					return failf(1, "refusing to replace the key for %s with one for %s; use --force\n", oldEmail, newEmail)
+				}
+				if !confirm(question) {
+					infof(os.Stderr, "Aborted.\n")
+					return 1
+				}
+			}
+		}
+		cfg.APIKey = *key
+		cfg.BaseConfig.BaseURL = *baseURL
+		if err := config.Save(cfg); err != nil {
+			return fail(1, "Failed to save config:", err)
+		}
+		infof(os.Stdout, "API key saved.\n")
+		return 0
+	}
 }
 
-func runWhoAmI(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("whoami", flag.ExitOnError)
-	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
-	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
-	_ = fs.Parse(args)
-	if *apiKey == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "Missing API key. Use set-key or --api-key or SIMPLELOGIN_API_KEY.")
-		return 2
-	}
-	c := api.NewClient(*baseURL, *apiKey)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// accountEmail returns the email of the account key belongs to.
+func accountEmail(ctx context.Context, baseURL, key string) (string, error) {
+	c, err := newClient(baseURL, key)
+	if err != nil {
+		return "", err
+	}
 	ui, err := c.UserInfo(ctx)
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return 1
+		return "", err
 	}
-	_, _ = fmt.Printf("%s (%s) premium=%v\n", ui.Name, ui.Email, ui.IsPremium)
-	return 0
+	return ui.Email, nil
+}
+
+func whoAmICommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	asJSON := fs.Bool("json", false, "Print the full account info as JSON (like the global --json, but errors stay plain text)")
+	withStats := fs.Bool("with-stats", false, "Also fetch alias statistics")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or SIMPLELOGIN_API_KEY.")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ui, err := c.UserInfo(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		// the account info is what whoami is for; the extras only get a warning
+		out := whoamiOutput{UserInfo: ui}
+		if canCreate, err := c.CanCreateAlias(ctx); err != nil {
+			infof(os.Stderr, "warning: cannot tell whether alias creation is enabled: %v\n", err)
+		} else {
+			out.CanCreateAlias = &canCreate
+		}
+		if *withStats {
+			st, err := c.Stats(ctx)
+			switch {
+			case errors.Is(err, api.ErrStatsUnavailable):
+				out.StatsUnavailable = true
+			case err != nil:
+				infof(os.Stderr, "warning: cannot fetch stats: %v\n", err)
+				out.StatsUnavailable = true
+			default:
+				out.Stats = &st
+			}
+		}
+		if global.json || *asJSON {
+			if err := printJSON(out); err != nil {
+				return fail(1, err)
+			}
+			return 0
+		}
+		_, _ = fmt.Printf("%s (%s) premium=%v\n", ui.Name, ui.Email, ui.IsPremium)
+		creation := "enabled"
+		switch {
+		case out.CanCreateAlias == nil:
+			creation = "unknown"
+		case !*out.CanCreateAlias:
+			creation = "disabled (free plan limit reached or account restricted)"
+		}
+		_, _ = fmt.Println("alias creation:", creation)
+		if out.Stats != nil {
+			_, _ = fmt.Printf("aliases=%d forwards=%d blocks=%d replies=%d\n", out.Stats.NbAlias, out.Stats.NbForward, out.Stats.NbBlock, out.Stats.NbReply)
+		}
+		if out.StatsUnavailable {
+			_, _ = fmt.Println("stats unavailable")
+		}
+		return 0
+	}
+}
+
+// whoamiOutput is the whoami --json document: every UserInfo field, whether
+// the account can create aliases (left out when that could not be checked),
+// and the account stats under "stats" when --with-stats is given, or
+// "stats_unavailable" when the server has no stats or they could not be
+// fetched.
+type whoamiOutput struct {
+	api.UserInfo
+	CanCreateAlias   *bool      `json:"can_create_alias,omitempty"`
+	Stats            *api.Stats `json:"stats,omitempty"`
+	StatsUnavailable bool       `json:"stats_unavailable,omitempty"`
 }
 
-func runOptions(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("options", flag.ExitOnError)
+func optionsCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
 	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
 	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
 	hostname := fs.String("hostname", "", "Website hostname to tailor suggestions")
-	_ = fs.Parse(args)
-	if *apiKey == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "Missing API key. Use set-key or --api-key or env.")
-		return 2
-	}
-	c := api.NewClient(*baseURL, *apiKey)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	res, err := c.AliasOptions(ctx, *hostname)
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return 1
+	sortBy := fs.String("sort", "alpha", "Suffix order: alpha, premium (free first) or custom (custom domains first)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		less, ok := suffixOrders[*sortBy]
+		if !ok {
+			return failf(2, "invalid --sort %q: want alpha, premium or custom\n", *sortBy)
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		res, err := c.AliasOptions(ctx, *hostname)
+		if err != nil {
+			return fail(1, err)
+		}
+		sort.SliceStable(res.Suffixes, func(i, j int) bool { return less(res.Suffixes[i], res.Suffixes[j]) })
+		if global.json {
+			if err := printJSON(res); err != nil {
+				return fail(1, err)
+			}
+			return 0
+		}
+		_, _ = fmt.Println("can_create:", res.CanCreate)
+		_, _ = fmt.Println("prefix_suggestion:", res.PrefixSuggestion)
+		_, _ = fmt.Println("suffixes:")
+		for _, s := range res.Suffixes {
+			kind := "public"
+			if s.IsCustom {
+				kind = "custom"
+			}
+			prem := ""
+			if s.IsPremium {
+				prem = " (premium)"
+			}
+			_, _ = fmt.Printf("  - %s [%s]%s\n", s.Suffix, kind, prem)
+		}
+		return 0
 	}
-	sort.Slice(res.Suffixes, func(i, j int) bool { return res.Suffixes[i].Suffix < res.Suffixes[j].Suffix })
-	_, _ = fmt.Println("can_create:", res.CanCreate)
-	_, _ = fmt.Println("prefix_suggestion:", res.PrefixSuggestion)
-	_, _ = fmt.Println("suffixes:")
-	for _, s := range res.Suffixes {
-		kind := "public"
-		if s.IsCustom {
-			kind = "custom"
+}
+
+// suffixOrders are the options --sort orders. Each falls back to the
+// alphabetical order within a group.
+var suffixOrders = map[string]func(a, b api.SuffixOption) bool{
+	"alpha": func(a, b api.SuffixOption) bool { return a.Suffix < b.Suffix },
+	"premium": func(a, b api.SuffixOption) bool {
+		if a.IsPremium != b.IsPremium {
+			return !a.IsPremium
 		}
-		prem := ""
-		if s.IsPremium {
-			prem = " (premium)"
+		return a.Suffix < b.Suffix
+	},
+	"custom": func(a, b api.SuffixOption) bool {
+		if a.IsCustom != b.IsCustom {
+			return a.IsCustom
 		}
-		_, _ = fmt.Printf("  - %s [%s]%s\n", s.Suffix, kind, prem)
+		return a.Suffix < b.Suffix
+	},
+}
+
+// createRandomAlias creates a random alias and makes sure it carries name:
+// servers that ignore the name in the create body get a follow-up update.
+func createRandomAlias(ctx context.Context, c *api.Client, hostname, mode string, note, name *string) (api.Alias, error) {
+	a, err := c.CreateRandomAlias(ctx, hostname, mode, note, name)
+	if err != nil || name == nil || (a.Name != nil && *a.Name == *name) {
+		return a, err
+	}
+	verbosef("server ignored the name on create, setting it on %s\n", a.Email)
+	if err := c.UpdateAliasName(ctx, a.ID, name); err != nil {
+		return a, fmt.Errorf("alias %s created but setting its name failed: %w", a.Email, err)
+	}
+	a.Name = name
+	return a, nil
+}
+
+// defaultRandomMode returns the account's alias_generator setting, or "" to let
+// the server decide when the settings cannot be read.
+func defaultRandomMode(ctx context.Context, c *api.Client) string {
+	st, err := c.Settings(ctx)
+	if err != nil {
+		verbosef("could not read account settings (%v); the server picks the mode\n", err)
+		return ""
+	}
+	verbosef("mode: %s (account alias_generator setting)\n", st.AliasGenerator)
+	return st.AliasGenerator
+}
+
+// printRandomSummary describes a multi-alias random creation before it starts.
+func printRandomSummary(baseURL string, count int, mode, hostname, note string) {
+	if mode == "" {
+		mode = "account default"
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "About to create %d random aliases on %s\n", count, baseURL)
+	_, _ = fmt.Fprintf(os.Stderr, "  mode:     %s\n", mode)
+	_, _ = fmt.Fprintln(os.Stderr, "  mailbox:  default mailbox")
+	if hostname != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "  hostname: %s\n", hostname)
+	}
+	if strings.TrimSpace(note) != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "  note:     %s\n", note)
 	}
-	return 0
 }
 
-func runRandom(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("random", flag.ExitOnError)
+func randomCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
 	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
 	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
 	hostname := fs.String("hostname", "", "Website hostname to attach to the alias creation request")
-	mode := fs.String("mode", "", "Random alias mode: uuid or word (optional; defaults to user setting)")
+	mode := fs.String("mode", "", "Random alias mode: uuid or word (optional; defaults to the account's alias_generator setting)")
 	note := fs.String("note", "", "Optional note for the alias")
-	_ = fs.Parse(args)
-	if *apiKey == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "Missing API key. Use set-key or --api-key or env.")
-		return 2
-	}
-	c := api.NewClient(*baseURL, *apiKey)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	var notePtr *string
-	if strings.TrimSpace(*note) != "" {
-		n := *note
-		notePtr = &n
-	}
-	a, err := c.CreateRandomAlias(ctx, *hostname, *mode, notePtr)
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return 1
+	name := fs.String("name", "", "Optional display name for the alias")
+	tags := tagsFlag{}
+	fs.Var(tags, "tag", "Add a `key=value` tag to the note; repeatable")
+	nameFromHost := fs.Bool("name-from-hostname", false, "Use --hostname (without www.) as the alias name unless --name is given")
+	checkQuotaFlag := fs.Bool("check-quota", false, "Refuse to create the alias locally when the free-plan limit is reached")
+	count := fs.Int("count", 1, "Number of random aliases to create")
+	concurrency := fs.Int("concurrency", 3, "With --count, how many aliases to create in parallel")
+	yes := fs.Bool("yes", false, "With --count, skip the confirmation summary")
+	metricsFile := fs.String("metrics-file", "", "With --count, write Prometheus textfile-collector metrics for this run to this file")
+	var pace pacer
+	fs.Var(newDurationFlag(&pace.interval, 0), "min-interval", "With --count, wait at least this long between consecutive creates, e.g. 2s")
+	var noNewline bool
+	fs.BoolVar(&noNewline, "no-newline", false, "Print the alias email without a trailing newline (single alias only)")
+	fs.BoolVar(&noNewline, "n", false, "Shorthand for --no-newline")
+	var result resultOutput
+	result.register(fs)
+	var limits lengthLimits
+	limits.register(fs)
+	remember := fs.Bool("remember", cfg.BaseConfig.RememberLast, "Record the created alias for the last command")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *count < 1 || *concurrency < 1 {
+			return fail(2, "--count and --concurrency must be at least 1")
+		}
+		if err := result.validate(); err != nil {
+			return fail(2, err)
+		}
+		if result.envVar != "" && *count > 1 {
+			return fail(2, "--env-var names a single alias and cannot be combined with --count")
+		}
+		if *nameFromHost {
+			if err := nameFromHostname(name, *hostname); err != nil {
+				return fail(2, err)
+			}
+		}
+		*note = noteWithTags(*note, tags)
+		if !limits.check(*note, *name) {
+			return 2
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		// allow 30s per round of concurrent creations, plus the pacing
+		rounds := (*count + *concurrency - 1) / *concurrency
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(rounds)*30*time.Second+time.Duration(*count)*pace.interval)
+		defer cancel()
+		if *checkQuotaFlag {
+			if err := checkQuota(ctx, c); err != nil {
+				return fail(1, err)
+			}
+		}
+		if strings.TrimSpace(*mode) == "" {
+			*mode = defaultRandomMode(ctx, c)
+		}
+		var notePtr, namePtr *string
+		if strings.TrimSpace(*note) != "" {
+			n := *note
+			notePtr = &n
+		}
+		if strings.TrimSpace(*name) != "" {
+			n := *name
+			namePtr = &n
+		}
+		if *count > 1 {
+			if !*yes && stdinIsTerminal() {
+				printRandomSummary(c.BaseURL(), *count, *mode, *hostname, *note)
+				if !confirm(fmt.Sprintf("Create %d aliases?", *count)) {
+					infof(os.Stderr, "Aborted.\n")
+					return 1
+				}
+			}
+			start := time.Now()
+			created, failed := runBounded(*count, *concurrency, func(int) error {
+				if err := pace.wait(ctx); err != nil {
+					return err
+				}
+				a, err := createRandomAlias(ctx, c, *hostname, *mode, notePtr, namePtr)
+				if err != nil {
+					return err
+				}
+				printMu.Lock()
+				if *remember {
+					rememberAlias(a)
+				}
+				defer printMu.Unlock()
+				return result.emit(a, false)
+			})
+			saveMetrics(*metricsFile, "random", bulkMetrics{created: created, failed: failed, duration: time.Since(start)})
+			if failed > 0 {
+				return failf(1, "created %d of %d aliases, %d failed\n", created, *count, failed)
+			}
+			return 0
+		}
+		a, err := createRandomAlias(ctx, c, *hostname, *mode, notePtr, namePtr)
+		if err != nil {
+			return fail(1, err)
+		}
+		if *remember {
+			rememberAlias(a)
+		}
+		if err := result.emit(a, noNewline); err != nil {
+			return fail(1, err)
+		}
+		return 0
 	}
-	_, _ = fmt.Println(a.Email)
-	return 0
 }
 
-func runDeleteAlias(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+func deleteAliasCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
 	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
 	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
 	hostname := fs.String("hostname", "", "Website hostname to attach to the alias creation request")
-	email := fs.String("email", "", "Email of the alias to delete (required)")
-	_ = fs.Parse(args)
-	if *apiKey == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "Missing API key. Use set-key or --api-key or env.")
-		return 2
-	}
-	if *email == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "--email is required")
-		return 2
-	}
-	c := api.NewClient(*baseURL, *apiKey)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if err := c.DeleteAliasByEmail(ctx, *hostname, *email); err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
-	_, _ = fmt.Println("Alias deleted:", *email)
+	email := fs.String("email", "", "Email of the alias to delete")
+	reverseAlias := fs.String("reverse-alias", "", "Delete the alias owning the contact with this reverse-alias address")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *email == "" && *reverseAlias == "" {
+			return fail(2, "--email or --reverse-alias is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		if *email == "" {
+			// scanning every alias' contacts can take a while
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			a, err := resolveAlias(ctx, c, aliasRef{reverseAlias: *reverseAlias})
+			if err != nil {
+				return fail(1, err)
+			}
+			if err := c.DeleteAlias(ctx, a.ID, *hostname); err != nil {
+				return fail(1, err)
+			}
+			return reportDeleted(a.Email)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := c.DeleteAliasByEmail(ctx, *hostname, *email); err != nil {
+			return fail(1, err)
+		}
+		return reportDeleted(*email)
+	}
+}
+
+// reportDeleted confirms a deleted alias, as {"email":...,"deleted":true}
+// with --json.
+func reportDeleted(email string) int {
+	if global.json {
+		if err := printJSON(struct {
+			Email   string `json:"email"`
+			Deleted bool   `json:"deleted"`
+		}{email, true}); err != nil {
+			return fail(1, err)
+		}
+		return 0
+	}
+	infof(os.Stdout, "Alias deleted: %s\n", email)
 	return 0
 }
-func runCustom(args []string, cfg config.SecureConfig) int {
-	fs := flag.NewFlagSet("custom", flag.ExitOnError)
+func customCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
 	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
 	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
 	hostname := fs.String("hostname", "", "Website hostname to attach to the alias creation request")
 	prefix := fs.String("prefix", "", "Alias prefix to use (required)")
 	signedSuffix := fs.String("signed-suffix", "", "Signed suffix token (from options)")
+	suffixIndex := fs.Int("suffix-index", 0, "Pick the Nth suffix (1-based) in the order the interactive picker shows, without prompting")
 	suffix := fs.String("suffix", "", "Plain suffix to select from options (will auto-pick matching signed suffix)")
-	mailboxIDsCSV := fs.String("mailbox-ids", "", "Comma-separated mailbox IDs owning the alias (defaults to default mailbox)")
+	mailboxIDsCSV := fs.String("mailbox-ids", "", "Comma-separated mailbox IDs owning the alias (defaults to the config's default_mailbox_ids, else the default mailbox)")
+	validateMailboxes := fs.Bool("validate-mailboxes", true, "Check --mailbox-ids against your mailboxes before creating")
+	noValidateMailboxes := fs.Bool("no-validate-mailboxes", false, "Skip the --mailbox-ids check")
+	requireMailbox := fs.Bool("require-explicit-mailbox", cfg.BaseConfig.RequireExplicitMailbox, "Fail unless --mailbox-ids is given instead of using the configured or default mailbox")
 	note := fs.String("note", "", "Optional note")
 	name := fs.String("name", "", "Optional alias name")
-	_ = fs.Parse(args)
-	if *apiKey == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "Missing API key. Use set-key or --api-key or env.")
-		return 2
-	}
-	if *prefix == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "--prefix is required")
-		return 2
-	}
-	c := api.NewClient(*baseURL, *apiKey)
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
-	defer cancel()
-	ss := strings.TrimSpace(*signedSuffix)
-	if ss == "" {
-		if strings.TrimSpace(*suffix) == "" {
-			opt, err := c.AliasOptions(ctx, *hostname)
-			if err != nil {
-				_, _ = fmt.Fprintln(os.Stderr, err)
-				return 1
-			}
-			if len(opt.Suffixes) == 0 {
-				_, _ = fmt.Fprintln(os.Stderr, "no suffixes available")
-				return 1
-			}
-			sort.Slice(opt.Suffixes, func(i, j int) bool { return opt.Suffixes[i].Suffix < opt.Suffixes[j].Suffix })
-			_, _ = fmt.Println("Available suffixes:")
-			for i, s := range opt.Suffixes {
-				kind := "public"
-				if s.IsCustom {
-					kind = "custom"
+	tags := tagsFlag{}
+	fs.Var(tags, "tag", "Add a `key=value` tag to the note; repeatable")
+	nameFromHost := fs.Bool("name-from-hostname", false, "Use --hostname (without www.) as the alias name unless --name is given")
+	noPrefixCheck := fs.Bool("no-prefix-check", false, "Only warn (instead of failing) when the prefix breaks SimpleLogin's rules")
+	uniqueSuffix := fs.Bool("unique-suffix", false, "Append a random 4-hex-char token to the prefix and regenerate it if the alias already exists")
+	uniqueRetries := fs.Int("unique-retries", 3, "With --unique-suffix, how many times to regenerate the token on a collision")
+	skipExisting := fs.Bool("skip-existing", false, "Do nothing (and print the existing email) if the alias already exists")
+	checkQuotaFlag := fs.Bool("check-quota", false, "Refuse to create the alias locally when the free-plan limit is reached")
+	var noNewline bool
+	fs.BoolVar(&noNewline, "no-newline", false, "Print the alias email without a trailing newline")
+	fs.BoolVar(&noNewline, "n", false, "Shorthand for --no-newline")
+	var result resultOutput
+	result.register(fs)
+	var limits lengthLimits
+	limits.register(fs)
+	remember := fs.Bool("remember", cfg.BaseConfig.RememberLast, "Record the created alias for the last command")
+	prefixFromSite := fs.Bool("prefix-from-site", false, "Derive the prefix from --hostname's domain when --prefix is empty")
+	var preview bool
+	fs.BoolVar(&preview, "preview", false, "Print the email the alias would get and exit without creating it")
+	fs.BoolVar(&preview, "dry-run", false, "Same as --preview")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *prefix == "" && *prefixFromSite {
+			if *hostname == "" {
+				return fail(2, "--prefix-from-site needs --hostname")
+			}
+			*prefix = api.PrefixFromHost(*hostname)
+			if *prefix == "" {
+				return failf(2, "cannot derive a prefix from hostname %q\n", *hostname)
+			}
+			infof(os.Stderr, "prefix: %s\n", *prefix)
+		}
+		if *prefix == "" {
+			return fail(2, "--prefix is required")
+		}
+		if *nameFromHost {
+			if err := nameFromHostname(name, *hostname); err != nil {
+				return fail(2, err)
+			}
+		}
+		*note = noteWithTags(*note, tags)
+		if !limits.check(*note, *name) {
+			return 2
+		}
+		if err := result.validate(); err != nil {
+			return fail(2, err)
+		}
+		if *requireMailbox && strings.TrimSpace(*mailboxIDsCSV) == "" {
+			return fail(2, "--mailbox-ids is required (require-explicit-mailbox is set)")
+		}
+		if *suffixIndex < 0 || (*suffixIndex > 0 && (*suffix != "" || *signedSuffix != "")) {
+			return fail(2, "--suffix-index must be positive and cannot be combined with --suffix or --signed-suffix")
+		}
+		if *skipExisting && *uniqueSuffix {
+			return fail(2, "--skip-existing and --unique-suffix are mutually exclusive")
+		}
+		aliasPrefix := *prefix
+		if *uniqueSuffix {
+			aliasPrefix = uniquePrefix(*prefix)
+		}
+		if err := api.ValidatePrefix(aliasPrefix); err != nil {
+			if !*noPrefixCheck {
+				return fail(2, err)
+			}
+			infof(os.Stderr, "warning: %v\n", err)
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+		defer cancel()
+		if *checkQuotaFlag {
+			if err := checkQuota(ctx, c); err != nil {
+				return fail(1, err)
+			}
+		}
+		ss := strings.TrimSpace(*signedSuffix)
+		plainSuffix := strings.TrimSpace(*suffix)
+		if ss == "" {
+			if strings.TrimSpace(*suffix) == "" {
+				opt, err := c.AliasOptions(ctx, *hostname)
+				if err != nil {
+					return fail(1, err)
 				}
-				prem := ""
-				if s.IsPremium {
-					prem = " (premium)"
+				if !opt.CanCreate {
+					return fail(1, cannotCreateError(ctx, c))
 				}
-				_, _ = fmt.Printf("  %2d) %s [%s]%s\n", i+1, s.Suffix, kind, prem)
-			}
-			_, _ = fmt.Print("Pick a suffix [1-", len(opt.Suffixes), "]: ")
-			reader := bufio.NewReader(os.Stdin)
-			line, _ := reader.ReadString('\n')
-			line = strings.TrimSpace(line)
-			idx, err := strconv.Atoi(line)
-			if err != nil || idx < 1 || idx > len(opt.Suffixes) {
-				_, _ = fmt.Fprintln(os.Stderr, "invalid selection")
-				return 2
-			}
-			ss = opt.Suffixes[idx-1].SignedSuffix
-		} else {
+				if len(opt.Suffixes) == 0 {
+					return fail(1, "no suffixes available")
+				}
+				sort.Slice(opt.Suffixes, func(i, j int) bool { return opt.Suffixes[i].Suffix < opt.Suffixes[j].Suffix })
+				idx := *suffixIndex
+				if idx > len(opt.Suffixes) {
+					return failf(2, "--suffix-index %d is out of range: %d suffixes available\n", idx, len(opt.Suffixes))
+				}
+				if idx == 0 {
+					_, _ = fmt.Println("Available suffixes:")
+					for i, s := range opt.Suffixes {
+						kind := "public"
+						if s.IsCustom {
+							kind = "custom"
+						}
+						prem := ""
+						if s.IsPremium {
+							prem = " (premium)"
+						}
+						_, _ = fmt.Printf("  %2d) %s [%s]%s\n", i+1, s.Suffix, kind, prem)
+					}
+					_, _ = fmt.Print("Pick a suffix [1-", len(opt.Suffixes), "]: ")
+					reader := bufio.NewReader(os.Stdin)
+					line, _ := reader.ReadString('\n')
+					line = strings.TrimSpace(line)
+					idx, err = strconv.Atoi(line)
+					if err != nil || idx < 1 || idx > len(opt.Suffixes) {
+						return fail(2, "invalid selection")
+					}
+				}
+				ss = opt.Suffixes[idx-1].SignedSuffix
+				plainSuffix = opt.Suffixes[idx-1].Suffix
+			} else {
+				opt, err := c.AliasOptions(ctx, *hostname)
+				if err != nil {
+					return fail(1, err)
+				}
+				if !opt.CanCreate {
+					return fail(1, cannotCreateError(ctx, c))
+				}
+				ss, err = opt.SignedSuffixFor(*suffix)
+				if err != nil {
+					return fail(exitSuffixNotFound, err)
+				}
+			}
+		}
+		if (*skipExisting || preview) && plainSuffix == "" {
 			opt, err := c.AliasOptions(ctx, *hostname)
 			if err != nil {
-				_, _ = fmt.Fprintln(os.Stderr, err)
-				return 1
+				return fail(1, err)
 			}
 			for _, s := range opt.Suffixes {
-				if s.Suffix == *suffix {
-					ss = s.SignedSuffix
+				if s.SignedSuffix == ss {
+					plainSuffix = s.Suffix
 					break
 				}
 			}
-			if ss == "" {
-				_, _ = fmt.Fprintf(os.Stderr, "suffix %q not found in available options\n", *suffix)
-				return 2
+			if plainSuffix == "" {
+				return fail(2, "cannot tell the alias email from this signed suffix; use --suffix instead")
 			}
 		}
-	}
-	var ids []int
-	if strings.TrimSpace(*mailboxIDsCSV) != "" {
-		parts := strings.Split(*mailboxIDsCSV, ",")
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
+		if preview {
+			infof(os.Stderr, "preview only, nothing created\n")
+			if global.json {
+				if err := printJSON(struct {
+					Email string `json:"email"`
+				}{api.AliasEmail(aliasPrefix, plainSuffix)}); err != nil {
+					return fail(1, err)
+				}
+				return 0
+			}
+			printValue(api.AliasEmail(aliasPrefix, plainSuffix), noNewline)
+			return 0
+		}
+		if *skipExisting {
+			existing, err := c.FindAliasByEmail(ctx, api.AliasEmail(aliasPrefix, plainSuffix))
+			if err == nil {
+				infof(os.Stderr, "skipped: %s already exists\n", existing.Email)
+				if err := result.emit(existing, noNewline); err != nil {
+					return fail(1, err)
+				}
+				return 0
+			}
+			if !errors.Is(err, api.ErrAliasNotFound) {
+				return fail(1, err)
+			}
+		}
+		var ids []int
+		if strings.TrimSpace(*mailboxIDsCSV) != "" {
+			parts := strings.Split(*mailboxIDsCSV, ",")
+			for _, p := range parts {
+				p = strings.TrimSpace(p)
+				if p == "" {
+					continue
+				}
+				v, err := strconv.Atoi(p)
+				if err != nil {
+					return failf(2, "invalid mailbox id: %q\n", p)
+				}
+				ids = append(ids, v)
 			}
-			v, err := strconv.Atoi(p)
+			if *validateMailboxes && !*noValidateMailboxes {
+				if err := checkMailboxIDs(ctx, c, ids); err != nil {
+					return fail(2, err)
+				}
+			}
+		} else if len(cfg.BaseConfig.DefaultMailboxIDs) > 0 {
+			ids = cfg.BaseConfig.DefaultMailboxIDs
+			verbosef("mailboxes: %v (default_mailbox_ids from config)\n", ids)
+		} else {
+			mid, err := c.DefaultMailboxID(ctx)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "invalid mailbox id: %q\n", p)
-				return 2
+				return fail(1, "failed to determine default mailbox:", err)
 			}
-			ids = append(ids, v)
+			ids = []int{mid}
+		}
+		var notePtr, namePtr *string
+		if strings.TrimSpace(*note) != "" {
+			n := *note
+			notePtr = &n
+		}
+		if strings.TrimSpace(*name) != "" {
+			n := *name
+			namePtr = &n
+		}
+		a, err := c.CreateCustomAlias(ctx, *hostname, aliasPrefix, ss, ids, notePtr, namePtr)
+		for attempt := 0; *uniqueSuffix && attempt < *uniqueRetries && api.IsStatus(err, http.StatusConflict); attempt++ {
+			infof(os.Stderr, "prefix %s is taken, regenerating\n", aliasPrefix)
+			aliasPrefix = uniquePrefix(*prefix)
+			a, err = c.CreateCustomAlias(ctx, *hostname, aliasPrefix, ss, ids, notePtr, namePtr)
+		}
+		if api.IsSignedSuffixExpired(err) {
+			if plainSuffix == "" {
+				return fail(1, "the signed suffix has expired; run options again or pass --suffix so it can be refreshed automatically")
+			}
+			verbosef("signed suffix for %s expired, fetching a fresh one\n", plainSuffix)
+			opt, oerr := c.AliasOptions(ctx, *hostname)
+			if oerr != nil {
+				return fail(1, oerr)
+			}
+			if ss, err = opt.SignedSuffixFor(plainSuffix); err != nil {
+				return failf(exitSuffixNotFound, "the signed suffix expired and %s is no longer available: %v\n", plainSuffix, err)
+			}
+			a, err = c.CreateCustomAlias(ctx, *hostname, aliasPrefix, ss, ids, notePtr, namePtr)
 		}
-	} else {
-		mid, err := c.DefaultMailboxID(ctx)
 		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "failed to determine default mailbox:", err)
-			return 1
+			return fail(1, err)
 		}
-		ids = []int{mid}
-	}
-	var notePtr, namePtr *string
-	if strings.TrimSpace(*note) != "" {
-		n := *note
-		notePtr = &n
-	}
-	if strings.TrimSpace(*name) != "" {
-		n := *name
-		namePtr = &n
-	}
-	a, err := c.CreateCustomAlias(ctx, *hostname, *prefix, ss, ids, notePtr, namePtr)
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		return 1
+		if *uniqueSuffix {
+			infof(os.Stderr, "prefix: %s\n", aliasPrefix)
+		}
+		if *remember {
+			rememberAlias(a)
+		}
+		if err := result.emit(a, noNewline); err != nil {
+			return fail(1, err)
+		}
+		return 0
 	}
-	_, _ = fmt.Println(a.Email)
-	return 0
+}
+
+// uniquePrefix appends a short random hex token to prefix to avoid collisions.
+func uniquePrefix(prefix string) string {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
 }
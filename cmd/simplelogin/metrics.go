@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// bulkMetrics are the outcome counters of a bulk command run.
+type bulkMetrics struct {
+	created, skipped, failed int
+	duration                 time.Duration
+}
+
+// writeMetrics writes m in the Prometheus text format for node_exporter's
+// textfile collector, with metric names prefixed by simplelogin_<command>_.
+// The file is replaced atomically so a scrape never sees a partial file.
+func writeMetrics(path, command string, m bulkMetrics) error {
+	prefix := "simplelogin_" + command + "_"
+	var b strings.Builder
+	for _, c := range []struct {
+		name, help string
+		value      int
+	}{
+		{"created_total", "Aliases created in the last run.", m.created},
+		{"skipped_total", "Items skipped in the last run.", m.skipped},
+		{"failed_total", "Items that failed in the last run.", m.failed},
+	} {
+		_, _ = fmt.Fprintf(&b, "# HELP %s%s %s\n# TYPE %s%s counter\n%s%s %d\n", prefix, c.name, c.help, prefix, c.name, prefix, c.name, c.value)
+	}
+	_, _ = fmt.Fprintf(&b, "# HELP %sduration_seconds Duration of the last run.\n# TYPE %sduration_seconds gauge\n%sduration_seconds %g\n", prefix, prefix, prefix, m.duration.Seconds())
+	_, _ = fmt.Fprintf(&b, "# HELP %slast_run_timestamp_seconds When the last run finished.\n# TYPE %slast_run_timestamp_seconds gauge\n%slast_run_timestamp_seconds %d\n", prefix, prefix, prefix, time.Now().Unix())
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveMetrics writes the metrics file when path is set, warning on failure
+// without changing the command's outcome.
+func saveMetrics(path, command string, m bulkMetrics) {
+	if path == "" {
+		return
+	}
+	if err := writeMetrics(path, command, m); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "warning: could not write metrics file:", err)
+	}
+}
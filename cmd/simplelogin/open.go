@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"simplelogincli/pkg/config"
+)
+
+func openCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	id := fs.Int("id", 0, "Alias ID (skips the lookup by email)")
+	email := fs.String("email", "", "Alias email")
+	printURL := fs.Bool("print-url", false, "Print the URL instead of launching a browser")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *id <= 0 && *email == "" {
+			return fail(2, "--id or --email is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		aliasID := *id
+		if aliasID <= 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+			a, err := c.FindAliasByEmail(ctx, *email)
+			if err != nil {
+				return fail(1, err)
+			}
+			aliasID = a.ID
+		}
+		u := c.BaseURL() + "/dashboard/?" + url.Values{"highlight_alias_id": {strconv.Itoa(aliasID)}}.Encode()
+		if *printURL {
+			_, _ = fmt.Println(u)
+			return 0
+		}
+		if err := openBrowser(u); err != nil {
+			_, _ = fmt.Println(u)
+			infof(os.Stderr, "could not launch a browser (%v); open the URL above manually\n", err)
+		}
+		return 0
+	}
+}
+
+// openBrowser launches the platform's default browser on u.
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("no graphical display available")
+		}
+		cmd = exec.Command("xdg-open", u)
+	}
+	return cmd.Start()
+}
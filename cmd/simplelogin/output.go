@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"simplelogincli/pkg/api"
+)
+
+// printMu serializes output from concurrent workers.
+var printMu sync.Mutex
+
+// jsonIndent resolves the --indent global flag into the string used by json.MarshalIndent.
+func jsonIndent() (string, error) {
+	v := strings.TrimSpace(global.indent)
+	if strings.EqualFold(v, "tab") {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid --indent %q: want a number of spaces or 'tab'", global.indent)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// printJSON writes v to stdout, indented by default or on one line with --compact.
+func printJSON(v any) error {
+	return writeJSON(os.Stdout, v)
+}
+
+// writeJSON is printJSON for an arbitrary writer.
+func writeJSON(w io.Writer, v any) error {
+	var b []byte
+	var err error
+	if global.compact {
+		b, err = json.Marshal(v)
+	} else {
+		indent, ierr := jsonIndent()
+		if ierr != nil {
+			return ierr
+		}
+		b, err = json.MarshalIndent(v, "", indent)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// infof prints an informational message unless --quiet is set. Errors and
+// command results must not go through it.
+func infof(w io.Writer, format string, a ...any) {
+	if global.quiet {
+		return
+	}
+	_, _ = fmt.Fprintf(w, format, a...)
+}
+
+// verbosef prints a diagnostic message to stderr when --verbose is set.
+func verbosef(format string, a ...any) {
+	if !global.verbose {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, format, a...)
+}
+
+// fail reports an error on stderr and returns code, so commands can end with
+// "return fail(1, err)". The arguments are formatted as by fmt.Println. An
+// *api.RateLimitError among them replaces the message with advice on when to
+// try again and the code with exitRateLimited.
+func fail(code int, a ...any) int {
+	return reportError(code, strings.TrimSuffix(fmt.Sprintln(a...), "\n"), a...)
+}
+
+// failf is fail with a format string.
+func failf(code int, format string, a ...any) int {
+	return reportError(code, strings.TrimSuffix(fmt.Sprintf(format, a...), "\n"), a...)
+}
+
+// errorEnvelope is how errors are printed with --json.
+type errorEnvelope struct {
+	Error  string `json:"error"`
+	Code   int    `json:"code"`
+	Status int    `json:"status,omitempty"` // HTTP status, when the error came from the API
+}
+
+// reportError prints msg on stderr, as an errorEnvelope when --json is set,
+// and returns the exit code to use. The HTTP status is taken from the first
+// *api.APIError among a.
+func reportError(code int, msg string, a ...any) int {
+	var apiErr *api.APIError
+	for _, v := range a {
+		err, ok := v.(error)
+		if !ok {
+			continue
+		}
+		var rl *api.RateLimitError
+		if errors.As(err, &rl) {
+			code, msg = exitRateLimited, rateLimitMessage(rl)
+		}
+		if errors.As(err, &apiErr) {
+			break
+		}
+	}
+	printMu.Lock()
+	defer printMu.Unlock()
+	if !global.json {
+		_, _ = fmt.Fprintln(os.Stderr, msg)
+		return code
+	}
+	env := errorEnvelope{Error: msg, Code: code}
+	if apiErr != nil {
+		env.Status = apiErr.StatusCode
+	}
+	b, _ := json.Marshal(env)
+	_, _ = fmt.Fprintln(os.Stderr, string(b))
+	return code
+}
+
+// rateLimitMessage tells the user how long to back off after rl.
+func rateLimitMessage(rl *api.RateLimitError) string {
+	if rl.RetryAfter <= 0 {
+		return fmt.Sprintf("rate limited after %d attempts; try again later", rl.Attempts)
+	}
+	return fmt.Sprintf("rate limited after %d attempts; try again in %s", rl.Attempts, rl.RetryAfter.Round(time.Second))
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+func confirm(question string) bool {
+	_, _ = fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(line))
+	return a == "y" || a == "yes"
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal, so prompts
+// can be skipped when input is piped or the command runs unattended.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// printValue prints a single result such as an alias email, optionally
+// without the trailing newline for byte-exact shell pipelines.
+func printValue(v string, noNewline bool) {
+	if noNewline {
+		_, _ = fmt.Print(v)
+		return
+	}
+	_, _ = fmt.Println(v)
+}
+
+// loadTemplate parses an output template given inline or read from a file;
+// the two are mutually exclusive. It returns nil when neither is set. A
+// trailing newline is added to inline templates so each item gets its own
+// line; templates from files are used exactly as written.
+func loadTemplate(inline, file string) (*template.Template, error) {
+	if inline != "" && file != "" {
+		return nil, errors.New("--output-template and --template-file are mutually exclusive")
+	}
+	text := inline
+	name := "output-template"
+	switch {
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		text, name = string(b), file
+	case inline != "":
+		text += "\n"
+	default:
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return t, nil
+}
+
+// resultOutput holds the --env-var and --out flags shared by the commands that
+// create an alias.
+type resultOutput struct {
+	envVar string
+	out    string
+}
+
+func (o *resultOutput) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.envVar, "env-var", "", "Print NAME=<email>, ready for a .env file, instead of the bare email")
+	fs.StringVar(&o.out, "out", "", "Append the result to this file instead of printing it")
+}
+
+// validate checks --env-var is a usable variable name and that neither flag
+// is combined with --json, which prints the whole alias instead.
+func (o *resultOutput) validate() error {
+	if global.json && (o.envVar != "" || o.out != "") {
+		return errors.New("--json and --env-var/--out are mutually exclusive")
+	}
+	if o.envVar != "" && !envVarName.MatchString(o.envVar) {
+		return fmt.Errorf("invalid --env-var %q: use letters, digits and underscores, not starting with a digit", o.envVar)
+	}
+	return nil
+}
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// emit prints the created alias email, or appends it to --out as its own
+// line. With --json the whole alias is printed as JSON.
+func (o *resultOutput) emit(a api.Alias, noNewline bool) error {
+	if global.json {
+		return printJSON(a)
+	}
+	email := a.Email
+	v := email
+	if o.envVar != "" {
+		v = o.envVar + "=" + email
+	}
+	if o.out == "" {
+		printValue(v, noNewline)
+		return nil
+	}
+	f, err := os.OpenFile(o.out, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, v); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	infof(os.Stderr, "appended %s to %s\n", email, o.out)
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/config"
+)
+
+func pinCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	return setPinnedCommand(true, fs, cfg)
+}
+
+func unpinCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	return setPinnedCommand(false, fs, cfg)
+}
+
+// setPinnedCommand backs the pin and unpin commands.
+func setPinnedCommand(pinned bool, fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	emailsCSV := fs.String("emails", "", "Comma-separated alias emails")
+	file := fs.String("file", "", "File with one alias email per line ('#' starts a comment)")
+	concurrency := fs.Int("concurrency", 3, "How many aliases to update in parallel")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *concurrency < 1 {
+			return fail(2, "--concurrency must be at least 1")
+		}
+		emails := splitList(*emailsCSV)
+		if *file != "" {
+			fromFile, err := readEmailFile(*file)
+			if err != nil {
+				return fail(1, err)
+			}
+			emails = append(emails, fromFile...)
+		}
+		if len(emails) == 0 {
+			return fail(2, "--emails or --file is required")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		aliases, err := c.FindAliasesByEmail(ctx, emails)
+		if err != nil {
+			return fail(1, err)
+		}
+		state := "unpinned"
+		if pinned {
+			state = "pinned"
+		}
+		done, failed := runBounded(len(emails), *concurrency, func(i int) error {
+			a, ok := aliases[emails[i]]
+			if !ok {
+				return fmt.Errorf("%s: alias not found", emails[i])
+			}
+			if err := c.SetAliasPinned(ctx, a.ID, pinned); err != nil {
+				return fmt.Errorf("%s: %w", a.Email, err)
+			}
+			printMu.Lock()
+			defer printMu.Unlock()
+			_, _ = fmt.Printf("%s: %s\n", a.Email, state)
+			return nil
+		})
+		if failed > 0 {
+			return failf(1, "%s %d of %d aliases, %d failed\n", state, done, len(emails), failed)
+		}
+		return 0
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// readEmailFile reads one email per line, skipping blank lines and '#' comments.
+func readEmailFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// provisionCommand makes sure a mailbox exists and is verified, then creates a
+// custom alias delivering to it.
+func provisionCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	mailboxEmail := fs.String("mailbox-email", "", "Mailbox to deliver to; created if it does not exist (required)")
+	prefix := fs.String("prefix", "", "Alias prefix (required)")
+	suffix := fs.String("suffix", "", "Plain alias suffix from options (required)")
+	hostname := fs.String("hostname", "", "Website hostname to attach to the alias creation request")
+	note := fs.String("note", "", "Optional note")
+	var wait, poll time.Duration
+	fs.Var(newDurationFlag(&wait, 0), "wait", "How long to wait for the mailbox to be verified (0 fails right away)")
+	fs.Var(newDurationFlag(&poll, 10*time.Second), "poll", "With --wait, how often to check the mailbox")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if *mailboxEmail == "" || *prefix == "" || *suffix == "" {
+			return fail(2, "--mailbox-email, --prefix and --suffix are required")
+		}
+		if err := api.ValidatePrefix(*prefix); err != nil {
+			return fail(2, err)
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), wait+time.Minute)
+		defer cancel()
+
+		res, err := c.Mailboxes(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		mb, ok := findMailbox(res.Mailboxes, 0, *mailboxEmail)
+		if ok {
+			infof(os.Stderr, "mailbox %s exists (id=%d)\n", mb.Email, mb.ID)
+		} else {
+			mb, err = c.CreateMailbox(ctx, *mailboxEmail)
+			if err != nil {
+				return fail(1, "create mailbox:", err)
+			}
+			infof(os.Stderr, "mailbox created: %s (id=%d); a verification email was sent\n", mb.Email, mb.ID)
+		}
+		if !mb.Verified {
+			if wait <= 0 {
+				return failf(1, "mailbox %s is not verified yet: click the link in the verification email and run this again, or pass --wait 10m\n", mb.Email)
+			}
+			infof(os.Stderr, "waiting up to %s for %s to be verified...\n", wait, mb.Email)
+			if mb, err = waitForVerification(ctx, c, mb.ID, wait, poll); err != nil {
+				return fail(1, err)
+			}
+		}
+
+		opt, err := c.AliasOptions(ctx, *hostname)
+		if err != nil {
+			return fail(1, err)
+		}
+		ss, err := opt.SignedSuffixFor(*suffix)
+		if err != nil {
+			return fail(exitSuffixNotFound, err)
+		}
+		var notePtr *string
+		if *note != "" {
+			notePtr = note
+		}
+		a, err := c.CreateCustomAlias(ctx, *hostname, *prefix, ss, []int{mb.ID}, notePtr, nil)
+		if err != nil {
+			return fail(1, "create alias:", err)
+		}
+		infof(os.Stderr, "alias created: %s (id=%d) -> %s\n", a.Email, a.ID, mb.Email)
+		_, _ = fmt.Println(a.Email)
+		return 0
+	}
+}
+
+// waitForVerification polls the mailboxes until mailboxID is verified or wait runs out.
+func waitForVerification(ctx context.Context, c *api.Client, mailboxID int, wait, poll time.Duration) (api.Mailbox, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		if err := sleepUntil(ctx, min(poll, time.Until(deadline))); err != nil {
+			return api.Mailbox{}, err
+		}
+		res, err := c.Mailboxes(ctx)
+		if err != nil {
+			return api.Mailbox{}, err
+		}
+		mb, ok := findMailbox(res.Mailboxes, mailboxID, "")
+		if !ok {
+			return api.Mailbox{}, errors.New("mailbox disappeared while waiting for verification")
+		}
+		if mb.Verified {
+			return mb, nil
+		}
+		if !time.Now().Before(deadline) {
+			return api.Mailbox{}, fmt.Errorf("mailbox %s still not verified after %s", mb.Email, wait)
+		}
+	}
+}
+
+// sleepUntil waits for d or until ctx is done.
+func sleepUntil(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
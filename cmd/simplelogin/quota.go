@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"simplelogincli/pkg/api"
+)
+
+// checkQuota refuses alias creation on a free plan that has used up its
+// alias allowance. Premium and trial accounts are not limited, and servers
+// without a stats endpoint are not checked.
+func checkQuota(ctx context.Context, c *api.Client) error {
+	ui, err := c.UserInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if ui.IsPremium || ui.InTrial || ui.MaxAliasFreePlan <= 0 {
+		return nil
+	}
+	st, err := c.Stats(ctx)
+	if errors.Is(err, api.ErrStatsUnavailable) {
+		infof(os.Stderr, "stats unavailable on this server, skipping the quota check\n")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if st.NbAlias >= ui.MaxAliasFreePlan {
+		return fmt.Errorf("free plan alias limit reached: %d of %d aliases used; delete some aliases or upgrade to create more", st.NbAlias, ui.MaxAliasFreePlan)
+	}
+	return nil
+}
+
+// cannotCreateError explains an options response with can_create=false,
+// mentioning the free-plan limit when UserInfo reports one.
+func cannotCreateError(ctx context.Context, c *api.Client) error {
+	msg := "your account cannot create more aliases — free plan limit reached or account restricted"
+	if ui, err := c.UserInfo(ctx); err == nil && !ui.IsPremium && ui.MaxAliasFreePlan > 0 {
+		msg += fmt.Sprintf(" (free plan allows %d aliases)", ui.MaxAliasFreePlan)
+	}
+	return errors.New(msg)
+}
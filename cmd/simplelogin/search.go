@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+func searchCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	query := fs.String("query", "", "Text to search for in alias emails, names and notes (required)")
+	local := fs.Bool("local", false, "Search the local index (see index build) instead of the API")
+	var maxAge time.Duration
+	fs.Var(newDurationFlag(&maxAge, 0), "max-age", "With --local, rebuild the index first when it is older than this, e.g. 1d (0: never)")
+	return func() int {
+		if strings.TrimSpace(*query) == "" {
+			return fail(2, "--query is required")
+		}
+		if *local {
+			return searchIndex(*query, maxAge, *baseURL, *apiKey)
+		}
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		found, err := searchAliases(ctx, c, *query)
+		if err != nil {
+			return fail(1, err)
+		}
+		if len(found) == 0 {
+			infof(os.Stderr, "no aliases match %q\n", *query)
+			return 0
+		}
+		for _, a := range found {
+			printAliasLine(a, false)
+		}
+		return 0
+	}
+}
+
+// searchAliases uses the server-side search and falls back to filtering every
+// page locally when the server does not support it.
+func searchAliases(ctx context.Context, c *api.Client, query string) ([]api.Alias, error) {
+	var found []api.Alias
+	for page := 0; ; page++ {
+		res, err := c.SearchAliases(ctx, page, query)
+		if page == 0 && searchUnsupported(err) {
+			infof(os.Stderr, "server-side search unavailable, filtering locally\n")
+			return filterAliases(ctx, c, query)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Aliases) == 0 {
+			return found, nil
+		}
+		found = append(found, res.Aliases...)
+	}
+}
+
+func searchUnsupported(err error) bool {
+	return api.IsStatus(err, http.StatusNotFound) || api.IsStatus(err, http.StatusMethodNotAllowed) ||
+		api.IsStatus(err, http.StatusNotImplemented)
+}
+
+// filterAliases pages through all aliases keeping those whose email, name or note contains query.
+func filterAliases(ctx context.Context, c *api.Client, query string) ([]api.Alias, error) {
+	q := strings.ToLower(query)
+	var found []api.Alias
+	for page := 0; ; page++ {
+		res, err := c.ListAliases(ctx, page, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Aliases) == 0 {
+			return found, nil
+		}
+		for _, a := range res.Aliases {
+			var name, note string
+			if a.Name != nil {
+				name = *a.Name
+			}
+			if a.Note != nil {
+				note = *a.Note
+			}
+			if aliasMatches(q, a.Email, name, note) {
+				found = append(found, a)
+			}
+		}
+		//sleep to avoid rate limiting
+		time.Sleep(700 * time.Millisecond)
+	}
+}
+
+// aliasMatches reports whether the lowercase query q occurs in any of fields.
+func aliasMatches(q string, fields ...string) bool {
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchIndex searches the local alias index, building it first when there is
+// none, it was built for another base URL or API key, or it is older than
+// maxAge. Without an API key the stored index is trusted as is.
+func searchIndex(query string, maxAge time.Duration, baseURL, apiKey string) int {
+	idx, ok, err := config.LoadIndex()
+	if err != nil {
+		return fail(1, "read index:", err)
+	}
+	otherAccount := apiKey != "" && idx.Account != config.KeyFingerprint(apiKey)
+	if ok && otherAccount {
+		verbosef("the local index was built with another API key; rebuilding it\n")
+	}
+	stale := !ok || idx.BaseURL != baseURL || otherAccount || (maxAge > 0 && time.Since(idx.BuiltAt) > maxAge)
+	if stale {
+		if apiKey == "" {
+			return fail(2, "the local index is missing or stale and rebuilding it needs an API key. Use set-key or --api-key or env.")
+		}
+		c, err := newClient(baseURL, apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stderr, "building the local index...\n")
+		if idx, err = rebuildIndex(c, baseURL, apiKey); err != nil {
+			return fail(1, err)
+		}
+	}
+	infof(os.Stderr, "index built %s (%d aliases)\n", relativeTime(idx.BuiltAt, time.Now()), len(idx.Aliases))
+	q := strings.ToLower(query)
+	matches := 0
+	for _, ia := range idx.Aliases {
+		if aliasMatches(q, ia.Email, ia.Name, ia.Note) {
+			matches++
+			printAliasLine(api.Alias{ID: ia.ID, Email: ia.Email, Enabled: ia.Enabled}, false)
+		}
+	}
+	if matches == 0 {
+		infof(os.Stderr, "no aliases match %q\n", query)
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+func settingsShowCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	asJSON := fs.Bool("json", false, "Print the settings as JSON (like the global --json, but errors stay plain text)")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		st, err := c.Settings(ctx)
+		if err != nil {
+			return fail(1, err)
+		}
+		if global.json || *asJSON {
+			if err := printJSON(st); err != nil {
+				return fail(1, err)
+			}
+			return 0
+		}
+		printSettings(st)
+		return 0
+	}
+}
+
+func settingsSetCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	senderFormat := fs.String("sender-format", "", "How senders appear in forwarded emails: "+strings.Join(api.SenderFormats, ", "))
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		var u api.SettingsUpdate
+		if *senderFormat != "" {
+			f := strings.ToUpper(strings.TrimSpace(*senderFormat))
+			if !slices.Contains(api.SenderFormats, f) {
+				return failf(2, "invalid --sender-format %q: want one of %s\n", *senderFormat, strings.Join(api.SenderFormats, ", "))
+			}
+			u.SenderFormat = &f
+		}
+		if u == (api.SettingsUpdate{}) {
+			return fail(2, "nothing to change: pass --sender-format")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		st, err := c.UpdateSettings(ctx, u)
+		if err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stderr, "Settings updated.\n")
+		printSettings(st)
+		return 0
+	}
+}
+
+func printSettings(st api.Settings) {
+	_, _ = fmt.Println("alias_generator:", st.AliasGenerator)
+	_, _ = fmt.Println("notification:", st.Notification)
+	_, _ = fmt.Println("random_alias_default_domain:", st.RandomAliasDefaultDomain)
+	_, _ = fmt.Println("random_alias_suffix:", st.RandomAliasSuffix)
+	_, _ = fmt.Println("sender_format:", st.SenderFormat)
+}
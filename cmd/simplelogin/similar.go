@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// similarCommand lists the current suffix options, marking the ones on the same
+// domain as an existing alias so another alias can be made next to it.
+func similarCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	email := fs.String("email", "", "Existing alias email (required)")
+	hostname := fs.String("hostname", "", "Website hostname to tailor suggestions")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		domain := api.Alias{Email: *email}.Domain()
+		if domain == "" {
+			return fail(2, "--email must be an alias address")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		res, err := c.AliasOptions(ctx, *hostname)
+		if err != nil {
+			return fail(1, err)
+		}
+		sort.Slice(res.Suffixes, func(i, j int) bool { return res.Suffixes[i].Suffix < res.Suffixes[j].Suffix })
+		matches := 0
+		for _, s := range res.Suffixes {
+			mark := " "
+			if s.Domain() == domain {
+				mark = "*"
+				matches++
+			}
+			_, _ = fmt.Printf("%s %s\n", mark, s.Suffix)
+		}
+		if matches == 0 {
+			infof(os.Stderr, "no current suffix is on %s\n", domain)
+			return exitNoData
+		}
+		infof(os.Stderr, "* marks suffixes on %s; use one with custom --suffix\n", domain)
+		return 0
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"simplelogincli/pkg/api"
+)
+
+// tagsFlag collects repeated --tag key=value flags; a later value for the
+// same key wins.
+type tagsFlag map[string]string
+
+func (t tagsFlag) String() string {
+	pairs := make([]string, 0, len(t))
+	for k, v := range t {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (t tagsFlag) Set(s string) error {
+	k, v, err := api.ParseTag(s)
+	if err != nil {
+		return err
+	}
+	t[k] = v
+	return nil
+}
+
+// noteWithTags returns note with tags appended in the note tag format, or
+// note unchanged when there are no tags.
+func noteWithTags(note string, tags tagsFlag) string {
+	if len(tags) == 0 {
+		return note
+	}
+	text, existing := api.ParseNoteTags(note)
+	for k, v := range tags {
+		existing[k] = v
+	}
+	return api.FormatNoteTags(text, existing)
+}
+
+// hasTags reports whether the alias' note carries every tag in want.
+func hasTags(a api.Alias, want tagsFlag) bool {
+	if len(want) == 0 {
+		return true
+	}
+	if a.Note == nil {
+		return false
+	}
+	_, tags := api.ParseNoteTags(*a.Note)
+	for k, v := range want {
+		if got, ok := tags[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"simplelogincli/pkg/api"
+	"simplelogincli/pkg/config"
+)
+
+// updateCommand changes an alias' note, name, pinned or enabled state. Only the
+// flags given are sent, so --note "" clears the note while leaving out --note
+// keeps it.
+func updateCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	var ref aliasRef
+	ref.register(fs)
+	note := fs.String("note", "", "New note; an empty value clears it")
+	name := fs.String("name", "", "New display name; an empty value clears it")
+	pin := fs.Bool("pin", false, "Pin the alias")
+	unpin := fs.Bool("unpin", false, "Unpin the alias")
+	enable := fs.Bool("enable", false, "Enable the alias")
+	disable := fs.Bool("disable", false, "Disable the alias")
+	tags := tagsFlag{}
+	fs.Var(tags, "tag", "Add or replace a `key=value` tag in the note; repeatable")
+	return func() int {
+		if *apiKey == "" {
+			return fail(2, "Missing API key. Use set-key or --api-key or env.")
+		}
+		if ref.empty() {
+			return fail(2, "--id, --email or --reverse-alias is required")
+		}
+		if *pin && *unpin {
+			return fail(2, "--pin and --unpin are mutually exclusive")
+		}
+		if *enable && *disable {
+			return fail(2, "--enable and --disable are mutually exclusive")
+		}
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		var u api.AliasUpdate
+		if set["note"] {
+			u.Note = note
+		}
+		if set["name"] {
+			u.Name = name
+		}
+		if *pin || *unpin {
+			u.Pinned = pin
+		}
+		if *enable || *disable {
+			u.Disabled = disable
+		}
+		if u == (api.AliasUpdate{}) && len(tags) == 0 {
+			return fail(2, "nothing to change: pass --note, --name, --tag, --pin/--unpin or --enable/--disable")
+		}
+		c, err := newClient(*baseURL, *apiKey)
+		if err != nil {
+			return fail(1, err)
+		}
+		timeout := 60 * time.Second
+		if ref.reverseAlias != "" {
+			timeout = 5 * time.Minute
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		a, err := resolveAlias(ctx, c, ref)
+		if err != nil {
+			return fail(1, err)
+		}
+		if len(tags) > 0 {
+			current := ""
+			if a.Note != nil {
+				current = *a.Note
+			}
+			if u.Note != nil {
+				current = *u.Note
+			}
+			n := noteWithTags(current, tags)
+			u.Note = &n
+		}
+		limits := lengthLimits{name: api.MaxNameLength}
+		if !limits.check(deref(u.Note), deref(u.Name)) {
+			return 2
+		}
+		if err := c.UpdateAlias(ctx, a.ID, u); err != nil {
+			return fail(1, err)
+		}
+		a, err = c.GetAlias(ctx, a.ID)
+		if err != nil {
+			return fail(1, err)
+		}
+		infof(os.Stderr, "Alias updated.\n")
+		printAliasState(a)
+		return 0
+	}
+}
+
+// deref returns *s, or "" for nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+
+	"simplelogincli/pkg/api"
+)
+
+// lengthLimits are the --max-note-length/--max-name-length/--no-length-check
+// flags. A note limit of 0 means the server has none to enforce: notes
+// longer than api.MaxNoteLength only get a warning.
+type lengthLimits struct {
+	note, name int
+	warnOnly   bool
+}
+
+// register adds the length flags, so every command that creates an alias
+// reads the same ones.
+func (l *lengthLimits) register(fs *flag.FlagSet) {
+	fs.IntVar(&l.note, "max-note-length", 0, "Fail when the note is longer than this many characters (0: only warn above 4096)")
+	fs.IntVar(&l.name, "max-name-length", api.MaxNameLength, "Maximum name length in characters")
+	fs.BoolVar(&l.warnOnly, "no-length-check", false, "Only warn (instead of failing) when the note or name is too long")
+}
+
+// check validates the note and name lengths. It reports false when the
+// command should stop; with warnOnly the problems are only printed.
+func (l lengthLimits) check(note, name string) bool {
+	errs := []error{api.ValidateLength("name", name, l.name)}
+	if l.note > 0 {
+		errs = append(errs, api.ValidateLength("note", note, l.note))
+	} else if err := api.ValidateLength("note", note, api.MaxNoteLength); err != nil {
+		infof(os.Stderr, "warning: %v (the server may still accept it)\n", err)
+	}
+	ok := true
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if l.warnOnly {
+			infof(os.Stderr, "warning: %v\n", err)
+			continue
+		}
+		fail(2, err)
+		ok = false
+	}
+	return ok
+}
+
+// nameFromHostname fills an empty --name from --hostname for --name-from-hostname;
+// an explicit name wins.
+func nameFromHostname(name *string, hostname string) error {
+	if *name != "" {
+		return nil
+	}
+	host := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(hostname)), "www.")
+	if host == "" {
+		return errors.New("--name-from-hostname needs --hostname")
+	}
+	*name = host
+	return nil
+}
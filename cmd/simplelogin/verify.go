@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"simplelogincli/pkg/config"
+)
+
+// verifyCommand checks the setup step by step and prints a pass/fail checklist.
+func verifyCommand(fs *flag.FlagSet, cfg config.SecureConfig) func() int {
+	baseURL := fs.String("base-url", cfg.BaseConfig.BaseURL, "SimpleLogin base URL")
+	apiKey := fs.String("api-key", cfg.APIKey, "API key (overrides stored key)")
+	return func() int {
+
+		failed := ""
+		step := func(name string, err error, detail string) {
+			if err != nil {
+				_, _ = fmt.Printf("[FAIL] %s: %v\n", name, err)
+				if failed == "" {
+					failed = name
+				}
+				return
+			}
+			_, _ = fmt.Printf("[ok]   %s: %s\n", name, detail)
+		}
+
+		path, _ := config.Path()
+		exists, err := config.CheckFile()
+		detail := path + " (not present, using defaults)"
+		if exists {
+			detail = path
+		}
+		step("config file", err, detail)
+
+		keyOK := *apiKey != ""
+		source := config.KeySource()
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "api-key" {
+				source = "--api-key"
+			}
+		})
+		if keyOK {
+			step("api key", nil, "from "+source)
+		} else {
+			step("api key", fmt.Errorf("none found; use set-key, --api-key or %s", config.KeySourceEnv), "")
+		}
+
+		urlErr := checkBaseURLs(*baseURL)
+		step("base url", urlErr, *baseURL)
+
+		if !keyOK || urlErr != nil {
+			_, _ = fmt.Println("[skip] api access: needs a key and a valid base URL")
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			email, err := accountEmail(ctx, *baseURL, *apiKey)
+			step("api access", err, "authenticated as "+email)
+		}
+
+		if failed != "" {
+			return failf(1, "setup check failed at: %s\n", failed)
+		}
+		return 0
+	}
+}
+
+// checkBaseURLs validates each entry of a comma-separated base URL list.
+func checkBaseURLs(list string) error {
+	for _, b := range strings.Split(list, ",") {
+		b = strings.TrimSpace(b)
+		u, err := url.Parse(b)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%q is not an http(s) URL", b)
+		}
+	}
+	return nil
+}
@@ -2,11 +2,15 @@ module simplelogincli
 
 go 1.25
 
-require github.com/zalando/go-keyring v0.2.6
+require (
+	github.com/zalando/go-keyring v0.2.6
+	golang.org/x/net v0.50.0
+)
 
 require (
 	al.essio.dev/pkg/shellescape v1.5.1 // indirect
 	github.com/danieljoos/wincred v1.2.2 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
-	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
 )
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,38 +12,201 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const DefaultBaseURL = "https://app.simplelogin.io"
 
 type Client struct {
-	baseURL string
-	hc      *http.Client
-	apiKey  string
+	baseURLs []string
+	active   atomic.Int32 // index into baseURLs of the last base URL that responded
+	hc       *http.Client
+	apiKey   string
+	timings  *Timings
+	retry    retryConfig
+
+	authHeader string // "" means "Authentication"
+	bearer     bool
+	readOnly   bool
+	strict     bool // treat 2xx bodies with an "error" field as failures
+
+	settingsMu sync.Mutex
+	settings   *Settings // cached by Settings
+}
+
+// DefaultTimeout bounds each HTTP request made by a client created without
+// a timeout of its own.
+const DefaultTimeout = 30 * time.Second
+
+// ClientOptions customizes NewClientWithOptions. The zero value gives the
+// same client as NewClient: a new http.Client with DefaultTimeout and
+// http.DefaultTransport.
+type ClientOptions struct {
+	// Timeout bounds each HTTP request; 0 means DefaultTimeout, or the
+	// timeout HTTPClient already has.
+	Timeout time.Duration
+	// HTTPClient sends the requests, e.g. with a custom transport for proxies
+	// or instrumentation. It is copied, so setting Timeout does not change
+	// the caller's client. nil means a new http.Client.
+	HTTPClient *http.Client
+	// Transport replaces the HTTP client's transport, e.g. to route through
+	// a proxy, add tracing headers or answer requests in tests without a
+	// server. nil keeps the client's transport. WithClientCertificate needs
+	// an *http.Transport.
+	Transport http.RoundTripper
 }
 
+// NewClient creates a client for baseURL, which may be a comma-separated list
+// of base URLs tried in order when a connection fails.
 func NewClient(baseURL, apiKey string) *Client {
-	if baseURL == "" {
-		baseURL = DefaultBaseURL
+	return NewClientWithOptions(baseURL, apiKey, ClientOptions{})
+}
+
+// NewClientWithOptions is NewClient with a custom HTTP client or timeout.
+func NewClientWithOptions(baseURL, apiKey string, opts ClientOptions) *Client {
+	hc := &http.Client{Timeout: DefaultTimeout}
+	if opts.HTTPClient != nil {
+		cp := *opts.HTTPClient
+		hc = &cp
+	}
+	if opts.Timeout > 0 {
+		hc.Timeout = opts.Timeout
+	}
+	if opts.Transport != nil {
+		hc.Transport = opts.Transport
+	}
+	if t, ok := hc.Transport.(*http.Transport); ok {
+		// WithClientCertificate changes the transport; keep the caller's intact
+		hc.Transport = t.Clone()
+	}
+	var bases []string
+	for _, b := range strings.Split(baseURL, ",") {
+		if b = strings.TrimRight(strings.TrimSpace(b), "/"); b != "" {
+			bases = append(bases, b)
+		}
+	}
+	if len(bases) == 0 {
+		bases = []string{DefaultBaseURL}
 	}
 	return &Client{
-		baseURL: strings.TrimRight(baseURL, "/"),
-		hc:      &http.Client{Timeout: 30 * time.Second},
-		apiKey:  apiKey,
+		baseURLs: bases,
+		hc:       hc,
+		apiKey:   apiKey,
+		retry: retryConfig{
+			max:           DefaultRetries,
+			backoff:       DefaultRetryBackoff,
+			rateLimitWait: 2 * time.Second,
+			maxRetryAfter: time.Minute,
+		},
+	}
+}
+
+// BaseURL returns the base URL currently in use (the last one that responded).
+func (c *Client) BaseURL() string {
+	return c.baseURLs[c.active.Load()]
+}
+
+// WithAuthHeader sends the API key in the named header instead of SimpleLogin's
+// non-standard "Authentication", for proxies that strip unknown headers.
+func (c *Client) WithAuthHeader(name string) {
+	c.authHeader = name
+}
+
+// WithBearerAuth sends the key as "Bearer <key>". Unless WithAuthHeader chose
+// another header, it goes in the standard Authorization header.
+func (c *Client) WithBearerAuth(on bool) {
+	c.bearer = on
+}
+
+// ErrReadOnly is returned for requests that would change server state on a
+// client put in read-only mode.
+var ErrReadOnly = errors.New("blocked in read-only mode")
+
+// WithReadOnly makes the client refuse every request that can change data
+// with ErrReadOnly before anything is sent. GET and HEAD requests and the
+// POST endpoints in queryPosts still go through.
+func (c *Client) WithReadOnly(on bool) {
+	c.readOnly = on
+}
+
+// WithStrictErrorDetection treats a 2xx response whose body is an object with
+// a non-empty top-level "error" string as a failure, for misconfigured
+// instances that report errors with status 200. It is off by default so a
+// legitimate field of that name is never mistaken for an error.
+func (c *Client) WithStrictErrorDetection(on bool) {
+	c.strict = on
+}
+
+// WithClientCertificate loads an X509 key pair from PEM files and presents it
+// on TLS connections, for servers behind an mTLS-enforcing proxy.
+func (c *Client) WithClientCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("load client certificate %s / key %s: %w", certFile, keyFile, err)
+	}
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.Certificates = append(t.TLSClientConfig.Certificates, cert)
+	return nil
+}
+
+// transport returns the client's own *http.Transport, installing a clone of
+// http.DefaultTransport on first use so the shared default is never mutated.
+// A custom RoundTripper of another type cannot be configured.
+func (c *Client) transport() (*http.Transport, error) {
+	switch t := c.hc.Transport.(type) {
+	case nil:
+		own := http.DefaultTransport.(*http.Transport).Clone()
+		c.hc.Transport = own
+		return own, nil
+	case *http.Transport:
+		return t, nil
+	default:
+		return nil, fmt.Errorf("cannot configure TLS on a %T transport; pass an *http.Transport", t)
 	}
 }
 
+// queryPosts lists the POST endpoints that only read data, such as the alias
+// search, so read-only mode lets them through.
+var queryPosts = map[string]bool{
+	"/api/v2/aliases": true,
+}
+
+// mutates reports whether a request can change data on the server.
+func mutates(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return false
+	case http.MethodPost:
+		return !queryPosts[path]
+	}
+	return true
+}
+
 func (c *Client) newReq(ctx context.Context, method, path string, body any, query url.Values) (*http.Request, error) {
+	if c.readOnly && mutates(method, path) {
+		return nil, fmt.Errorf("%s %s: %w", method, path, ErrReadOnly)
+	}
 	var r io.Reader
 	if body != nil {
-		b, err := json.Marshal(body)
-		if err != nil {
+		// Encode without HTML escaping so notes and names containing <, > or &
+		// go over the wire byte-for-byte, like any other UTF-8 text.
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(body); err != nil {
 			return nil, err
 		}
-		r = bytes.NewReader(b)
+		r = bytes.NewReader(buf.Bytes())
 	}
-	full := c.baseURL + path
+	full := c.BaseURL() + path
 	if len(query) > 0 {
 		if strings.Contains(full, "?") {
 			full += "&" + query.Encode()
@@ -54,8 +218,22 @@ func (c *Client) newReq(ctx context.Context, method, path string, body any, quer
 	if err != nil {
 		return nil, err
 	}
+	if id := TraceID(ctx); id != "" {
+		req.Header.Set(TraceHeader, id)
+	}
 	if c.apiKey != "" {
-		req.Header.Set("Authentication", c.apiKey)
+		name, value := c.authHeader, c.apiKey
+		if c.bearer {
+			value = "Bearer " + value
+		}
+		switch {
+		case name != "":
+		case c.bearer:
+			name = "Authorization"
+		default:
+			name = "Authentication"
+		}
+		req.Header.Set(name, value)
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -63,8 +241,98 @@ func (c *Client) newReq(ctx context.Context, method, path string, body any, quer
 	return req, nil
 }
 
-func (c *Client) doJSON(req *http.Request, out any) error {
+// do sends req and, on a connection failure (not an HTTP error status), retries
+// it against the base URLs after the one it was built for, in order. The first
+// base URL that answers is used for subsequent requests.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
 	resp, err := c.hc.Do(req)
+	if err == nil || len(c.baseURLs) < 2 {
+		return resp, err
+	}
+	// not c.active: a concurrent request may have moved it since req was built
+	from, ok := c.baseIndex(req)
+	if !ok {
+		return nil, err
+	}
+	for i := 1; i < len(c.baseURLs) && req.Context().Err() == nil; i++ {
+		to := (from + i) % len(c.baseURLs)
+		next, rerr := rebase(req, c.baseURLs[from], c.baseURLs[to])
+		if rerr != nil {
+			return nil, err
+		}
+		resp, nerr := c.hc.Do(next)
+		if nerr == nil {
+			c.active.Store(int32(to))
+			return resp, nil
+		}
+		err = nerr
+	}
+	return nil, err
+}
+
+// baseIndex returns the index of the base URL req's URL starts with, the
+// longest one when several match.
+func (c *Client) baseIndex(req *http.Request) (int, bool) {
+	u := req.URL.String()
+	from := -1
+	for i, base := range c.baseURLs {
+		rest, ok := strings.CutPrefix(u, base)
+		if !ok || (rest != "" && rest[0] != '/' && rest[0] != '?') {
+			continue
+		}
+		if from < 0 || len(base) > len(c.baseURLs[from]) {
+			from = i
+		}
+	}
+	return from, from >= 0
+}
+
+// rebase clones req with its base URL swapped from one base to another.
+func rebase(req *http.Request, from, to string) (*http.Request, error) {
+	rest, ok := strings.CutPrefix(req.URL.String(), from)
+	if !ok {
+		return nil, fmt.Errorf("request URL %s is not under %s", req.URL, from)
+	}
+	u, err := url.Parse(to + rest)
+	if err != nil {
+		return nil, err
+	}
+	next := req.Clone(req.Context())
+	next.URL = u
+	next.Host = u.Host
+	if req.GetBody != nil {
+		if next.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+func (c *Client) doJSON(req *http.Request, out any) error {
+	if c.timings != nil {
+		start := time.Now()
+		defer func() { c.timings.record(req, time.Since(start)) }()
+	}
+	resp, err := c.do(req)
+	attempts := 1
+	for attempt := 0; attempt < c.retry.max && c.shouldRetry(req, resp, err); attempt++ {
+		wait, ok := c.retryWait(resp, attempt)
+		if !ok {
+			break
+		}
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if err = sleepCtx(req.Context(), wait); err != nil {
+			return err
+		}
+		if req, err = cloneForRetry(req); err != nil {
+			return err
+		}
+		resp, err = c.do(req)
+		attempts++
+	}
 	if err != nil {
 		return err
 	}
@@ -73,14 +341,22 @@ func (c *Client) doJSON(req *http.Request, out any) error {
 	if err != nil {
 		return err
 	}
+	var e struct {
+		Error string `json:"error"`
+	}
 	if resp.StatusCode >= 300 {
-		var e struct {
-			Error string `json:"error"`
-		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(b))}
 		if json.Unmarshal(b, &e) == nil && e.Error != "" {
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, e.Error)
+			apiErr.Message = e.Error
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait, _ := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			return &RateLimitError{Attempts: attempts, RetryAfter: wait, Err: apiErr}
 		}
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return apiErr
+	}
+	if c.strict && json.Unmarshal(b, &e) == nil && e.Error != "" {
+		return &APIError{StatusCode: resp.StatusCode, Message: e.Error}
 	}
 	if out != nil {
 		if err := json.Unmarshal(b, out); err != nil {
@@ -90,6 +366,52 @@ func (c *Client) doJSON(req *http.Request, out any) error {
 	return nil
 }
 
+// APIError is returned for responses with a non-2xx status code, and with
+// WithStrictErrorDetection for 2xx responses carrying an "error" field.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimitError is returned when the server still answers 429 Too Many
+// Requests once the retries are used up. It wraps the last *APIError, so
+// IsStatus(err, 429) holds for it as well.
+type RateLimitError struct {
+	Attempts   int           // requests made, including the first
+	RetryAfter time.Duration // from the last response's Retry-After header; 0 if absent
+	Err        *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// IsStatus reports whether err is an *APIError with the given HTTP status code.
+func IsStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// IsSignedSuffixExpired reports whether err is the server rejecting a custom
+// alias because its signed suffix is too old. SimpleLogin answers 412 for
+// that; the message check covers instances that use 400 instead.
+func IsSignedSuffixExpired(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusPreconditionFailed ||
+		(apiErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "expired"))
+}
+
 // Models
 
 type UserInfo struct {
@@ -101,6 +423,13 @@ type UserInfo struct {
 	MaxAliasFreePlan  int    `json:"max_alias_free_plan"`
 }
 
+type Stats struct {
+	NbAlias   int `json:"nb_alias"`
+	NbBlock   int `json:"nb_block"`
+	NbForward int `json:"nb_forward"`
+	NbReply   int `json:"nb_reply"`
+}
+
 type Alias struct {
 	ID                int     `json:"id"`
 	Email             string  `json:"email"`
@@ -112,6 +441,29 @@ type Alias struct {
 	NbForward         int     `json:"nb_forward"`
 	NbReply           int     `json:"nb_reply"`
 	Pinned            bool    `json:"pinned"`
+	// Mailboxes are the mailboxes the alias forwards to.
+	Mailboxes []AliasMailbox `json:"mailboxes,omitempty"`
+}
+
+// AliasMailbox is the short mailbox form embedded in an alias.
+type AliasMailbox struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+}
+
+// HasMailbox reports whether the alias forwards to the mailbox with this id.
+func (a Alias) HasMailbox(id int) bool {
+	for _, mb := range a.Mailboxes {
+		if mb.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Domain returns the part of the alias email after the @, or "" if there is none.
+func (a Alias) Domain() string {
+	return domainOf(a.Email)
 }
 
 type AliasesResponse struct {
@@ -124,27 +476,140 @@ type SuffixOption struct {
 	IsPremium    bool   `json:"is_premium"`
 }
 
+// Domain returns the domain aliases created with this suffix end up on.
+func (s SuffixOption) Domain() string {
+	return domainOf(s.Suffix)
+}
+
+func domainOf(addr string) string {
+	i := strings.LastIndex(addr, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(addr[i+1:])
+}
+
 type AliasOptionsResponse struct {
 	CanCreate        bool           `json:"can_create"`
 	PrefixSuggestion string         `json:"prefix_suggestion"`
 	Suffixes         []SuffixOption `json:"suffixes"`
 }
 
+// ErrSuffixNotFound is returned by SignedSuffixFor when the suffix is not offered.
+var ErrSuffixNotFound = errors.New("suffix not found in available options")
+
+// SignedSuffixFor returns the signed suffix matching the plain suffix. The
+// error lists the suffixes that are available.
+func (o AliasOptionsResponse) SignedSuffixFor(suffix string) (string, error) {
+	avail := make([]string, 0, len(o.Suffixes))
+	for _, s := range o.Suffixes {
+		if s.Suffix == suffix {
+			return s.SignedSuffix, nil
+		}
+		avail = append(avail, s.Suffix)
+	}
+	return "", fmt.Errorf("%w: %q (available: %s)", ErrSuffixNotFound, suffix, strings.Join(avail, ", "))
+}
+
+// Settings are the account-wide preferences (GET /api/setting).
+type Settings struct {
+	AliasGenerator           string `json:"alias_generator"`
+	Notification             bool   `json:"notification"`
+	RandomAliasDefaultDomain string `json:"random_alias_default_domain"`
+	SenderFormat             string `json:"sender_format"`
+	RandomAliasSuffix        string `json:"random_alias_suffix"`
+}
+
+// SenderFormats are the sender_format values the server accepts, controlling
+// how the original sender appears in forwarded emails.
+var SenderFormats = []string{"AT", "A", "NAME_ONLY", "AT_ONLY", "NO_NAME"}
+
+// SettingsUpdate holds the settings to change; nil fields are left as they are.
+type SettingsUpdate struct {
+	AliasGenerator           *string `json:"alias_generator,omitempty"`
+	Notification             *bool   `json:"notification,omitempty"`
+	RandomAliasDefaultDomain *string `json:"random_alias_default_domain,omitempty"`
+	SenderFormat             *string `json:"sender_format,omitempty"`
+	RandomAliasSuffix        *string `json:"random_alias_suffix,omitempty"`
+}
+
 type Mailbox struct {
 	ID       int    `json:"id"`
 	Email    string `json:"email"`
 	Default  bool   `json:"default"`
 	Verified bool   `json:"verified"`
+	NbAlias  int    `json:"nb_alias"`
 }
 
 type MailboxesResponse struct {
 	Mailboxes []Mailbox `json:"mailboxes"`
 }
 
+// Contact is a sender that has written to an alias, with the reverse alias
+// used to reply to it.
+type Contact struct {
+	ID                  int    `json:"id"`
+	Contact             string `json:"contact"`
+	CreationTimestamp   int64  `json:"creation_timestamp"`
+	ReverseAlias        string `json:"reverse_alias"`
+	ReverseAliasAddress string `json:"reverse_alias_address"`
+	BlockForward        bool   `json:"block_forward"`
+	// Existed is set by CreateContact when the alias already had this contact.
+	Existed bool `json:"existed"`
+}
+
+type ContactsResponse struct {
+	Contacts []Contact `json:"contacts"`
+}
+
+// Activity is one email event on an alias: a forward, reply, block or bounce
+// between a contact and the alias.
+type Activity struct {
+	Action       string `json:"action"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Timestamp    int64  `json:"timestamp"`
+	ReverseAlias string `json:"reverse_alias"`
+}
+
+type activitiesResponse struct {
+	Activities []Activity `json:"activities"`
+}
+
 // Requests
+//
+// Optional string fields are pointers: nil leaves the field out of the body,
+// while a pointer to "" sends "" explicitly (e.g. to create an alias with an
+// empty note rather than the server's default).
 
 type createRandomAliasRequest struct {
 	Note *string `json:"note,omitempty"`
+	// Name is only honored by newer servers; older ones ignore it.
+	Name *string `json:"name,omitempty"`
+}
+
+type updateAliasNameRequest struct {
+	Name *string `json:"name"`
+}
+
+type updateAliasPinnedRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+type createContactRequest struct {
+	Contact string `json:"contact"`
+}
+
+type searchAliasesRequest struct {
+	Query string `json:"query"`
+}
+
+type createMailboxRequest struct {
+	Email string `json:"email"`
+}
+
+type deleteMailboxRequest struct {
+	TransferAliasesTo int `json:"transfer_aliases_to"`
 }
 
 type createCustomAliasRequest struct {
@@ -166,10 +631,31 @@ func (c *Client) UserInfo(ctx context.Context) (UserInfo, error) {
 	return out, c.doJSON(req, &out)
 }
 
+// ErrStatsUnavailable is returned by Stats when the server has no /api/stats
+// endpoint, as on some self-hosted versions.
+var ErrStatsUnavailable = errors.New("stats unavailable")
+
+// Stats returns the account's alias counters. A 404 is reported as
+// ErrStatsUnavailable so callers can carry on without them.
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	req, err := c.newReq(ctx, http.MethodGet, "/api/stats", nil, nil)
+	if err != nil {
+		return Stats{}, err
+	}
+	var out Stats
+	if err := c.doJSON(req, &out); err != nil {
+		if IsStatus(err, http.StatusNotFound) {
+			return Stats{}, fmt.Errorf("%w: %w", ErrStatsUnavailable, err)
+		}
+		return Stats{}, err
+	}
+	return out, nil
+}
+
 func (c *Client) AliasOptions(ctx context.Context, hostname string) (AliasOptionsResponse, error) {
 	q := url.Values{}
-	if strings.TrimSpace(hostname) != "" {
-		q.Set("hostname", hostname)
+	if h := NormalizeHostname(hostname); h != "" {
+		q.Set("hostname", h)
 	}
 	req, err := c.newReq(ctx, http.MethodGet, "/api/v5/alias/options", nil, q)
 	if err != nil {
@@ -179,17 +665,69 @@ func (c *Client) AliasOptions(ctx context.Context, hostname string) (AliasOption
 	return out, c.doJSON(req, &out)
 }
 
-func (c *Client) CreateRandomAlias(ctx context.Context, hostname, mode string, note *string) (Alias, error) {
+// Settings fetches the account settings (GET /api/setting). The result is
+// cached for the lifetime of the client.
+func (c *Client) Settings(ctx context.Context) (Settings, error) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	if c.settings != nil {
+		return *c.settings, nil
+	}
+	req, err := c.newReq(ctx, http.MethodGet, "/api/setting", nil, nil)
+	if err != nil {
+		return Settings{}, err
+	}
+	var out Settings
+	if err := c.doJSON(req, &out); err != nil {
+		return Settings{}, err
+	}
+	c.settings = &out
+	return out, nil
+}
+
+// UpdateSettings changes the account settings set in u and returns the
+// settings as the server has them afterwards.
+func (c *Client) UpdateSettings(ctx context.Context, u SettingsUpdate) (Settings, error) {
+	req, err := c.newReq(ctx, http.MethodPatch, "/api/setting", u, nil)
+	if err != nil {
+		return Settings{}, err
+	}
+	var out Settings
+	if err := c.doJSON(req, &out); err != nil {
+		return Settings{}, err
+	}
+	c.settingsMu.Lock()
+	c.settings = &out
+	c.settingsMu.Unlock()
+	return out, nil
+}
+
+// CanCreateAlias reports whether the account may create aliases at all. Neither
+// UserInfo nor the settings expose this, so it is read from the alias options.
+func (c *Client) CanCreateAlias(ctx context.Context) (bool, error) {
+	opt, err := c.AliasOptions(ctx, "")
+	if err != nil {
+		return false, err
+	}
+	return opt.CanCreate, nil
+}
+
+// CreateRandomAlias creates a random alias (POST /api/alias/random/new). note
+// and name are sent when non-nil, even if empty. Older servers ignore name:
+// callers that need it should check the returned alias and fall back to
+// UpdateAliasName.
+func (c *Client) CreateRandomAlias(ctx context.Context, hostname, mode string, note, name *string) (Alias, error) {
 	q := url.Values{}
-	if strings.TrimSpace(hostname) != "" {
-		q.Set("hostname", hostname)
+	if h := NormalizeHostname(hostname); h != "" {
+		q.Set("hostname", h)
 	}
 	if m := strings.ToLower(strings.TrimSpace(mode)); m != "" {
 		q.Set("mode", m)
 	}
-	var body *createRandomAliasRequest
-	if note != nil && *note != "" {
-		body = &createRandomAliasRequest{Note: note}
+	// body stays a nil interface (no request body) unless there is something to send
+	var body any
+	if note != nil || name != nil {
+		body = createRandomAliasRequest{Note: note, Name: name}
 	}
 	req, err := c.newReq(ctx, http.MethodPost, "/api/alias/random/new", body, q)
 	if err != nil {
@@ -199,10 +737,13 @@ func (c *Client) CreateRandomAlias(ctx context.Context, hostname, mode string, n
 	return out, c.doJSON(req, &out)
 }
 
+// CreateCustomAlias creates an alias from a prefix and a signed suffix
+// (POST /api/v3/alias/custom/new). note and name are sent when non-nil, even
+// if empty.
 func (c *Client) CreateCustomAlias(ctx context.Context, hostname, aliasPrefix, signedSuffix string, mailboxIDs []int, note, name *string) (Alias, error) {
 	q := url.Values{}
-	if strings.TrimSpace(hostname) != "" {
-		q.Set("hostname", hostname)
+	if h := NormalizeHostname(hostname); h != "" {
+		q.Set("hostname", h)
 	}
 	body := createCustomAliasRequest{
 		AliasPrefix:  aliasPrefix,
@@ -249,12 +790,38 @@ func (c *Client) DefaultMailboxID(ctx context.Context) (int, error) {
 	return m.Mailboxes[0].ID, nil
 }
 
+// CreateMailbox adds a mailbox (POST /api/mailboxes). The server sends a
+// verification email; the mailbox cannot receive aliases until it is verified.
+func (c *Client) CreateMailbox(ctx context.Context, email string) (Mailbox, error) {
+	req, err := c.newReq(ctx, http.MethodPost, "/api/mailboxes", createMailboxRequest{Email: email}, nil)
+	if err != nil {
+		return Mailbox{}, err
+	}
+	var out Mailbox
+	return out, c.doJSON(req, &out)
+}
+
+// DeleteMailbox removes a mailbox (DELETE /api/mailboxes/:mailbox_id). Aliases
+// owned by it are moved to the mailbox transferTo, or deleted when transferTo
+// is DeleteMailboxAliases.
+func (c *Client) DeleteMailbox(ctx context.Context, mailboxID, transferTo int) error {
+	body := deleteMailboxRequest{TransferAliasesTo: transferTo}
+	req, err := c.newReq(ctx, http.MethodDelete, "/api/mailboxes/"+strconv.Itoa(mailboxID), body, nil)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, nil)
+}
+
+// DeleteMailboxAliases tells DeleteMailbox to delete the mailbox's aliases instead of transferring them.
+const DeleteMailboxAliases = -1
+
 // DeleteAlias removes an alias by id (DELETE /api/aliases/:alias_id)
 func (c *Client) DeleteAlias(ctx context.Context, aliasID int, hostname string) error {
 	path := "/api/aliases/" + strconv.Itoa(aliasID)
 	q := url.Values{}
-	if strings.TrimSpace(hostname) != "" {
-		q.Set("hostname", hostname)
+	if h := NormalizeHostname(hostname); h != "" {
+		q.Set("hostname", h)
 	}
 	req, err := c.newReq(ctx, http.MethodDelete, path, nil, q)
 	if err != nil {
@@ -263,12 +830,87 @@ func (c *Client) DeleteAlias(ctx context.Context, aliasID int, hostname string)
 	return c.doJSON(req, nil)
 }
 
+// UpdateAliasName sets an alias' display name (PATCH /api/aliases/:alias_id).
+// A nil name clears it.
+func (c *Client) UpdateAliasName(ctx context.Context, aliasID int, name *string) error {
+	req, err := c.newReq(ctx, http.MethodPatch, "/api/aliases/"+strconv.Itoa(aliasID), updateAliasNameRequest{Name: name}, nil)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, nil)
+}
+
+// SetAliasPinned pins or unpins an alias (PATCH /api/aliases/:alias_id).
+func (c *Client) SetAliasPinned(ctx context.Context, aliasID int, pinned bool) error {
+	req, err := c.newReq(ctx, http.MethodPatch, "/api/aliases/"+strconv.Itoa(aliasID), updateAliasPinnedRequest{Pinned: pinned}, nil)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, nil)
+}
+
+// AliasUpdate holds the alias fields to change; nil fields are left as they
+// are and a pointer to "" clears the note or name.
+type AliasUpdate struct {
+	Note   *string `json:"note,omitempty"`
+	Name   *string `json:"name,omitempty"`
+	Pinned *bool   `json:"pinned,omitempty"`
+	// Disabled goes through the toggle endpoint, since PATCH cannot change it.
+	Disabled *bool `json:"-"`
+}
+
+// UpdateAlias changes the fields set in u (PATCH /api/aliases/:alias_id).
+// Disabled is applied by toggling the alias when its state differs.
+func (c *Client) UpdateAlias(ctx context.Context, aliasID int, u AliasUpdate) error {
+	if u.Note != nil || u.Name != nil || u.Pinned != nil {
+		req, err := c.newReq(ctx, http.MethodPatch, "/api/aliases/"+strconv.Itoa(aliasID), u, nil)
+		if err != nil {
+			return err
+		}
+		if err := c.doJSON(req, nil); err != nil {
+			return err
+		}
+	}
+	if u.Disabled == nil {
+		return nil
+	}
+	a, err := c.GetAlias(ctx, aliasID)
+	if err != nil {
+		return err
+	}
+	if a.Enabled == !*u.Disabled {
+		return nil
+	}
+	_, err = c.ToggleAlias(ctx, aliasID)
+	return err
+}
+
+// AliasFilter restricts the aliases returned by ListAliasesFiltered server-side.
+type AliasFilter string
+
+const (
+	AliasFilterNone     AliasFilter = ""
+	AliasFilterPinned   AliasFilter = "pinned"
+	AliasFilterEnabled  AliasFilter = "enabled"
+	AliasFilterDisabled AliasFilter = "disabled"
+)
+
 func (c *Client) ListAliases(ctx context.Context, page int, hostname string) (AliasesResponse, error) {
+	return c.ListAliasesFiltered(ctx, page, hostname, AliasFilterNone)
+}
+
+// ListAliasesFiltered fetches one page of aliases (GET /api/v2/aliases), optionally
+// restricted to pinned, enabled or disabled aliases.
+func (c *Client) ListAliasesFiltered(ctx context.Context, page int, hostname string, filter AliasFilter) (AliasesResponse, error) {
 	path := "/api/v2/aliases"
 	query := url.Values{}
 	query.Add("page_id", strconv.Itoa(page))
-	if strings.TrimSpace(hostname) != "" {
-		query.Set("hostname", hostname)
+	if h := NormalizeHostname(hostname); h != "" {
+		query.Set("hostname", h)
+	}
+	if filter != AliasFilterNone {
+		// the server only checks for the presence of the key
+		query.Set(string(filter), "")
 	}
 	req, err := c.newReq(ctx, http.MethodGet, path, nil, query)
 	if err != nil {
@@ -279,24 +921,336 @@ func (c *Client) ListAliases(ctx context.Context, page int, hostname string) (Al
 	return out, errJson
 }
 
-// DeleteAliasBy email removes an alias by email (DELETE /api/aliases/:alias_id)
-func (c *Client) DeleteAliasByEmail(ctx context.Context, hostname, email string) error {
+// AliasPageSize is how many aliases SimpleLogin returns per page.
+const AliasPageSize = 20
+
+// maxAliasPages bounds ListAllAliases in case a server keeps returning full pages.
+const maxAliasPages = 1000
+
+// aliasPageDelay is the pause between the pages ListAllAliases and
+// AliasesModifiedSince fetch.
+var aliasPageDelay = 700 * time.Millisecond
+
+// waitPage sleeps aliasPageDelay to avoid rate limiting, returning early with
+// ctx's error when it is cancelled.
+func waitPage(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(aliasPageDelay):
+		return nil
+	}
+}
+
+// ListAllAliases fetches every page of aliases, starting at page 0, until a
+// page comes back with fewer than AliasPageSize aliases.
+func (c *Client) ListAllAliases(ctx context.Context, hostname string) ([]Alias, error) {
+	var out []Alias
+	for page := 0; page < maxAliasPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := c.ListAliases(ctx, page, hostname)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		out = append(out, res.Aliases...)
+		if len(res.Aliases) < AliasPageSize {
+			return out, nil
+		}
+		if err := waitPage(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("list aliases: still getting full pages after %d pages, giving up", maxAliasPages)
+}
+
+// AliasesModifiedSince returns the aliases created at or after t. The API
+// has no filter on modification time, so this pages through the aliases,
+// which the server sorts pinned first and then newest first, and stops at the
+// first page holding an unpinned alias older than t. Edits to older aliases
+// are not detected.
+func (c *Client) AliasesModifiedSince(ctx context.Context, t time.Time) ([]Alias, error) {
+	since := t.Unix()
+	var out []Alias
+	for page := 0; ; page++ {
+		res, err := c.ListAliases(ctx, page, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Aliases) == 0 {
+			return out, nil
+		}
+		done := false
+		for _, a := range res.Aliases {
+			if a.CreationTimestamp >= since {
+				out = append(out, a)
+			} else if !a.Pinned {
+				done = true
+			}
+		}
+		if done {
+			return out, nil
+		}
+		if err := waitPage(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// SearchAliases runs a server-side search over the account's aliases
+// (POST /api/v2/aliases?page_id=N with {"query": ...}) and returns one page of matches.
+func (c *Client) SearchAliases(ctx context.Context, pageID int, query string) (AliasesResponse, error) {
+	q := url.Values{}
+	q.Set("page_id", strconv.Itoa(pageID))
+	req, err := c.newReq(ctx, http.MethodPost, "/api/v2/aliases", searchAliasesRequest{Query: query}, q)
+	if err != nil {
+		return AliasesResponse{}, err
+	}
+	var out AliasesResponse
+	return out, c.doJSON(req, &out)
+}
+
+var ErrAliasNotFound = errors.New("alias not found")
+
+// FindAliasByEmail pages through the account's aliases looking for email,
+// compared case-insensitively after trimming surrounding whitespace. It returns
+// ErrInvalidEmail for input that is not an address and ErrAliasNotFound when
+// no alias matches.
+func (c *Client) FindAliasByEmail(ctx context.Context, email string) (Alias, error) {
+	return c.findAliasByEmail(ctx, "", email)
+}
+
+// findAliasByEmail is FindAliasByEmail listing the aliases with hostname, as
+// DeleteAliasByEmail does.
+func (c *Client) findAliasByEmail(ctx context.Context, hostname, email string) (Alias, error) {
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return Alias{}, err
+	}
 	for i := 0; ; i++ {
 		aliases, err := c.ListAliases(ctx, i, hostname)
 		if err != nil {
-			return err
+			return Alias{}, err
 		}
 		if len(aliases.Aliases) == 0 {
 			break
 		}
 		//find alias using value provided by user
 		for _, alias := range aliases.Aliases {
-			if alias.Email == email {
-				return c.DeleteAlias(ctx, alias.ID, hostname)
+			if strings.EqualFold(alias.Email, email) {
+				return alias, nil
 			}
 		}
 		//sleep to avoid rate limiting
 		time.Sleep(700 * time.Millisecond)
 	}
-	return nil
+	return Alias{}, fmt.Errorf("%w: %s", ErrAliasNotFound, email)
+}
+
+// FindAliasesByEmail looks up several aliases in a single pass over the
+// account's aliases. Emails are compared like in FindAliasByEmail, and the
+// returned map is keyed by the emails as passed. Emails that do not match any
+// alias, or are not addresses, are missing from it.
+func (c *Client) FindAliasesByEmail(ctx context.Context, emails []string) (map[string]Alias, error) {
+	want := make(map[string][]string, len(emails))
+	for _, e := range emails {
+		norm, err := NormalizeEmail(e)
+		if err != nil {
+			continue
+		}
+		want[norm] = append(want[norm], e)
+	}
+	found := make(map[string]Alias, len(emails))
+	matched := 0
+	for i := 0; matched < len(want); i++ {
+		aliases, err := c.ListAliases(ctx, i, "")
+		if err != nil {
+			return found, err
+		}
+		if len(aliases.Aliases) == 0 {
+			break
+		}
+		for _, alias := range aliases.Aliases {
+			norm, err := NormalizeEmail(alias.Email)
+			if err != nil {
+				continue
+			}
+			keys, ok := want[norm]
+			if !ok {
+				continue
+			}
+			if _, seen := found[keys[0]]; !seen {
+				matched++
+			}
+			for _, k := range keys {
+				found[k] = alias
+			}
+		}
+		if matched < len(want) {
+			//sleep to avoid rate limiting
+			time.Sleep(700 * time.Millisecond)
+		}
+	}
+	return found, nil
+}
+
+// DeleteAliasBy email removes an alias by email (DELETE /api/aliases/:alias_id)
+func (c *Client) DeleteAliasByEmail(ctx context.Context, hostname, email string) error {
+	alias, err := c.findAliasByEmail(ctx, hostname, email)
+	if errors.Is(err, ErrAliasNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.DeleteAlias(ctx, alias.ID, hostname)
+}
+
+// ListContacts fetches one page of an alias' contacts (GET /api/aliases/:alias_id/contacts).
+func (c *Client) ListContacts(ctx context.Context, aliasID, pageID int) ([]Contact, error) {
+	query := url.Values{}
+	query.Set("page_id", strconv.Itoa(pageID))
+	req, err := c.newReq(ctx, http.MethodGet, "/api/aliases/"+strconv.Itoa(aliasID)+"/contacts", nil, query)
+	if err != nil {
+		return nil, err
+	}
+	var out ContactsResponse
+	if err := c.doJSON(req, &out); err != nil {
+		return nil, err
+	}
+	return out.Contacts, nil
+}
+
+// AliasActivities fetches one page of an alias' activity log, newest first
+// (GET /api/aliases/:alias_id/activities).
+func (c *Client) AliasActivities(ctx context.Context, aliasID, pageID int) ([]Activity, error) {
+	query := url.Values{}
+	query.Set("page_id", strconv.Itoa(pageID))
+	req, err := c.newReq(ctx, http.MethodGet, "/api/aliases/"+strconv.Itoa(aliasID)+"/activities", nil, query)
+	if err != nil {
+		return nil, err
+	}
+	var out activitiesResponse
+	if err := c.doJSON(req, &out); err != nil {
+		return nil, err
+	}
+	return out.Activities, nil
+}
+
+// CreateContact adds a contact to an alias (POST /api/aliases/:alias_id/contacts)
+// and returns it with the reverse alias to write to so that the email is sent
+// from the alias. An existing contact is returned with Existed set.
+func (c *Client) CreateContact(ctx context.Context, aliasID int, contact string) (Contact, error) {
+	req, err := c.newReq(ctx, http.MethodPost, "/api/aliases/"+strconv.Itoa(aliasID)+"/contacts", createContactRequest{Contact: contact}, nil)
+	if err != nil {
+		return Contact{}, err
+	}
+	var out Contact
+	return out, c.doJSON(req, &out)
+}
+
+// ListAllContacts pages through all of an alias' contacts; an empty page marks the end.
+func (c *Client) ListAllContacts(ctx context.Context, aliasID int) ([]Contact, error) {
+	var all []Contact
+	for p := 0; ; p++ {
+		contacts, err := c.ListContacts(ctx, aliasID, p)
+		if err != nil {
+			return all, err
+		}
+		if len(contacts) == 0 {
+			return all, nil
+		}
+		all = append(all, contacts...)
+	}
+}
+
+// FindAliasByReverseAlias returns the alias owning the contact whose reverse
+// alias address is addr. There is no direct lookup endpoint, so every alias'
+// contacts are scanned; expect this to be slow on large accounts.
+// It returns ErrAliasNotFound when no contact matches.
+func (c *Client) FindAliasByReverseAlias(ctx context.Context, addr string) (Alias, error) {
+	for i := 0; ; i++ {
+		aliases, err := c.ListAliases(ctx, i, "")
+		if err != nil {
+			return Alias{}, err
+		}
+		if len(aliases.Aliases) == 0 {
+			break
+		}
+		for _, alias := range aliases.Aliases {
+			for p := 0; ; p++ {
+				contacts, err := c.ListContacts(ctx, alias.ID, p)
+				if err != nil {
+					return Alias{}, err
+				}
+				if len(contacts) == 0 {
+					break
+				}
+				for _, ct := range contacts {
+					if strings.EqualFold(ct.ReverseAliasAddress, addr) {
+						return alias, nil
+					}
+				}
+			}
+		}
+		//sleep to avoid rate limiting
+		time.Sleep(700 * time.Millisecond)
+	}
+	return Alias{}, fmt.Errorf("%w: no contact has reverse alias %s", ErrAliasNotFound, addr)
+}
+
+// GetAlias fetches a single alias by id (GET /api/aliases/:alias_id). A 404
+// is reported as ErrAliasNotFound, still carrying the *APIError.
+func (c *Client) GetAlias(ctx context.Context, aliasID int) (Alias, error) {
+	req, err := c.newReq(ctx, http.MethodGet, "/api/aliases/"+strconv.Itoa(aliasID), nil, nil)
+	if err != nil {
+		return Alias{}, err
+	}
+	var out Alias
+	if err := c.doJSON(req, &out); err != nil {
+		if IsStatus(err, http.StatusNotFound) {
+			return Alias{}, &aliasIDNotFoundError{id: aliasID, err: err}
+		}
+		return Alias{}, err
+	}
+	return out, nil
+}
+
+// aliasIDNotFoundError is GetAlias' error for a 404. It matches both
+// ErrAliasNotFound and the server's *APIError.
+type aliasIDNotFoundError struct {
+	id  int
+	err error
+}
+
+func (e *aliasIDNotFoundError) Error() string {
+	return fmt.Sprintf("%v: no alias with id %d", ErrAliasNotFound, e.id)
+}
+
+func (e *aliasIDNotFoundError) Unwrap() []error {
+	return []error{ErrAliasNotFound, e.err}
+}
+
+// ToggleAlias flips the enabled state of an alias (POST /api/aliases/:alias_id/toggle)
+// and returns the updated alias. Servers that only answer with {"enabled": bool}
+// get the rest of the alias re-fetched.
+func (c *Client) ToggleAlias(ctx context.Context, aliasID int) (Alias, error) {
+	req, err := c.newReq(ctx, http.MethodPost, "/api/aliases/"+strconv.Itoa(aliasID)+"/toggle", nil, nil)
+	if err != nil {
+		return Alias{}, err
+	}
+	var out Alias
+	if err := c.doJSON(req, &out); err != nil {
+		return Alias{}, err
+	}
+	if out.ID != 0 {
+		return out, nil
+	}
+	full, err := c.GetAlias(ctx, aliasID)
+	if err != nil {
+		return Alias{}, err
+	}
+	full.Enabled = out.Enabled
+	return full, nil
 }
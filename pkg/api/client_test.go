@@ -3,15 +3,61 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 )
 
+func TestNewClientWithOptionsTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	mine := &http.Client{}
+	c := NewClientWithOptions(ts.URL, "k", ClientOptions{Timeout: 20 * time.Millisecond, HTTPClient: mine})
+	if _, err := c.UserInfo(context.Background()); err == nil {
+		t.Fatal("request outlived the 20ms timeout")
+	}
+	if mine.Timeout != 0 {
+		t.Fatalf("caller's client was changed: timeout %s", mine.Timeout)
+	}
+	if c := NewClient(ts.URL, "k"); c.hc.Timeout != DefaultTimeout {
+		t.Fatalf("NewClient timeout = %s, want %s", c.hc.Timeout, DefaultTimeout)
+	}
+}
+
+// roundTripFunc answers requests in tests without a server.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewClientWithOptionsTransport(t *testing.T) {
+	var got *http.Request
+	c := NewClientWithOptions("https://sl.example", "key", ClientOptions{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"email":"me@example.com"}`))}, nil
+	})})
+	ui, err := c.UserInfo(context.Background())
+	if err != nil || ui.Email != "me@example.com" {
+		t.Fatalf("ui=%#v err=%v", ui, err)
+	}
+	if got.URL.String() != "https://sl.example/api/user_info" || got.Header.Get("Authentication") != "key" {
+		t.Fatalf("request %s with headers %v", got.URL, got.Header)
+	}
+	if err := c.WithClientCertificate(writeTestKeyPair(t)); err == nil || !strings.Contains(err.Error(), "roundTripFunc") {
+		t.Fatalf("WithClientCertificate on a custom transport: err = %v", err)
+	}
+}
+
 func TestUserInfo_OK(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/user_info" {
@@ -85,7 +131,7 @@ func TestCreateRandomAlias_QueryAndBody(t *testing.T) {
 	c := NewClient(ts.URL, "k")
 	n := "n"
 	ctx := context.Background()
-	a, err := c.CreateRandomAlias(ctx, "ex.com", "word", &n)
+	a, err := c.CreateRandomAlias(ctx, "ex.com", "word", &n, nil)
 	if err != nil {
 		t.Fatalf("CreateRandomAlias() error = %v", err)
 	}
@@ -194,7 +240,7 @@ func TestContentTypeWhenBody(t *testing.T) {
 	defer ts.Close()
 	c := NewClient(ts.URL, "k")
 	note := "n"
-	_, _ = c.CreateRandomAlias(context.Background(), "", "", &note)
+	_, _ = c.CreateRandomAlias(context.Background(), "", "", &note, nil)
 	if !strings.HasPrefix(got, "application/json") {
 		t.Fatalf("content-type = %q", got)
 	}
@@ -246,6 +292,117 @@ func TestListAliases_WithHostnameAndPage(t *testing.T) {
 	}
 }
 
+// noPageDelay drops the pause between alias pages for the rest of the test.
+func noPageDelay(t *testing.T) {
+	old := aliasPageDelay
+	aliasPageDelay = 0
+	t.Cleanup(func() { aliasPageDelay = old })
+}
+
+func TestListAllAliasesStopsAtShortPage(t *testing.T) {
+	noPageDelay(t)
+	var fetched int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		n := AliasPageSize
+		if r.URL.Query().Get("page_id") == "2" {
+			n = 3
+		}
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: make([]Alias, n)})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.ListAllAliases(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListAllAliases err=%v", err)
+	}
+	if len(got) != 2*AliasPageSize+3 || fetched != 3 {
+		t.Fatalf("got %d aliases in %d requests", len(got), fetched)
+	}
+}
+
+func TestListAllAliasesGivesUp(t *testing.T) {
+	noPageDelay(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: make([]Alias, AliasPageSize)})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.ListAllAliases(context.Background(), ""); err == nil || !strings.Contains(err.Error(), "giving up") {
+		t.Fatalf("err = %v, want giving up", err)
+	}
+}
+
+func TestListAllAliasesStopsOnCancel(t *testing.T) {
+	noPageDelay(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: make([]Alias, AliasPageSize)})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.ListAllAliases(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestListAllAliasesCancelInterruptsPageDelay(t *testing.T) {
+	old := aliasPageDelay
+	aliasPageDelay = time.Hour
+	t.Cleanup(func() { aliasPageDelay = old })
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: make([]Alias, AliasPageSize)})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	start := time.Now()
+	if _, err := c.ListAllAliases(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("returned after %v, want right after the cancel", d)
+	}
+}
+
+func TestAliasesModifiedSinceStopsAtOlderAlias(t *testing.T) {
+	noPageDelay(t)
+	pages := [][]Alias{
+		{{ID: 1, CreationTimestamp: 100, Pinned: true}, {ID: 2, CreationTimestamp: 500}, {ID: 3, CreationTimestamp: 400}},
+		{{ID: 4, CreationTimestamp: 300}, {ID: 5, CreationTimestamp: 150}},
+		{{ID: 6, CreationTimestamp: 120}},
+	}
+	var fetched []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("page_id")
+		fetched = append(fetched, p)
+		n, _ := strconv.Atoi(p)
+		var res AliasesResponse
+		if n < len(pages) {
+			res.Aliases = pages[n]
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.AliasesModifiedSince(context.Background(), time.Unix(300, 0))
+	if err != nil {
+		t.Fatalf("AliasesModifiedSince err=%v", err)
+	}
+	var ids []int
+	for _, a := range got {
+		ids = append(ids, a.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{2, 3, 4}) {
+		t.Fatalf("ids = %v, want [2 3 4]", ids)
+	}
+	if !reflect.DeepEqual(fetched, []string{"0", "1"}) {
+		t.Fatalf("fetched pages %v, want [0 1]", fetched)
+	}
+}
+
 func TestDeleteAlias_WithHostname(t *testing.T) {
 	deleted := false
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -275,6 +432,9 @@ func TestDeleteAliasByEmail_FoundFirstPage(t *testing.T) {
 	deleted := false
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
+		case r.URL.Query().Get("hostname") != "shop.example":
+			t.Errorf("%s %s: hostname not passed on", r.Method, r.URL)
+			http.NotFound(w, r)
 		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/aliases":
 			_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 5, Email: "t@sl"}}})
 		case r.Method == http.MethodDelete && r.URL.Path == "/api/aliases/5":
@@ -286,7 +446,7 @@ func TestDeleteAliasByEmail_FoundFirstPage(t *testing.T) {
 	}))
 	defer ts.Close()
 	c := NewClient(ts.URL, "k")
-	if err := c.DeleteAliasByEmail(context.Background(), "", "t@sl"); err != nil {
+	if err := c.DeleteAliasByEmail(context.Background(), "shop.example", "t@sl"); err != nil {
 		t.Fatalf("DeleteAliasByEmail err=%v", err)
 	}
 	if !deleted {
@@ -344,3 +504,814 @@ func Test_newReq_QueryConcatenation(t *testing.T) {
 		t.Fatalf("content-type set unexpectedly: %q", ct)
 	}
 }
+
+func TestListAliasesFiltered_Disabled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["disabled"]; !ok {
+			t.Fatalf("query = %s, want disabled key", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 3, Email: "off@sl"}}})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	out, err := c.ListAliasesFiltered(context.Background(), 0, "", AliasFilterDisabled)
+	if err != nil {
+		t.Fatalf("ListAliasesFiltered err=%v", err)
+	}
+	if len(out.Aliases) != 1 || out.Aliases[0].ID != 3 {
+		t.Fatalf("out = %#v", out)
+	}
+}
+
+func TestToggleAlias_RefetchesAlias(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/aliases/7/toggle":
+			_ = json.NewEncoder(w).Encode(map[string]bool{"enabled": false})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/aliases/7":
+			_ = json.NewEncoder(w).Encode(Alias{ID: 7, Email: "t@sl", NbForward: 4})
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	a, err := c.ToggleAlias(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("ToggleAlias err=%v", err)
+	}
+	if a.ID != 7 || a.Email != "t@sl" || a.Enabled || a.NbForward != 4 {
+		t.Fatalf("alias = %#v", a)
+	}
+}
+
+func TestFindAliasByEmail_NotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(AliasesResponse{})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	_, err := c.FindAliasByEmail(context.Background(), "x@sl")
+	if !errors.Is(err, ErrAliasNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestFailover_SkipsDeadBaseURL(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+	hits := 0
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["note"] != "n" {
+			t.Fatalf("body not replayed on failover: %#v", body)
+		}
+		_ = json.NewEncoder(w).Encode(Alias{ID: 1, Email: "f@sl"})
+	}))
+	defer live.Close()
+
+	c := NewClient(deadURL+","+live.URL, "k")
+	note := "n"
+	a, err := c.CreateRandomAlias(context.Background(), "", "", &note, nil)
+	if err != nil {
+		t.Fatalf("CreateRandomAlias err=%v", err)
+	}
+	if a.Email != "f@sl" || hits != 1 {
+		t.Fatalf("alias = %#v hits = %d", a, hits)
+	}
+	// the live base URL is remembered for the next request
+	if _, err := c.CreateRandomAlias(context.Background(), "", "", &note, nil); err != nil {
+		t.Fatalf("second call err=%v", err)
+	}
+	if got := c.baseURLs[c.active.Load()]; got != live.URL {
+		t.Fatalf("active base = %s, want %s", got, live.URL)
+	}
+}
+
+func TestFailover_FromTheRequestsBaseURL(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(UserInfo{Email: "u@sl"})
+	}))
+	defer live.Close()
+
+	c := NewClient(deadURL+","+live.URL, "k")
+	req, err := c.newReq(context.Background(), http.MethodGet, "/api/user_info", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another request failed over while this one was being built
+	c.active.Store(1)
+	resp, err := c.do(req)
+	if err != nil {
+		t.Fatalf("do err=%v, want failover to %s", err, live.URL)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestFailover_NotOnHTTPError(t *testing.T) {
+	backupHit := false
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "nope"})
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backupHit = true
+	}))
+	defer backup.Close()
+	c := NewClient(primary.URL+", "+backup.URL, "k")
+	if _, err := c.UserInfo(context.Background()); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("err = %v", err)
+	}
+	if backupHit {
+		t.Fatalf("backup should not be used for HTTP errors")
+	}
+}
+
+func TestErrorHandling_TypedAPIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "p.x@y already exists"})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	_, err := c.CreateCustomAlias(context.Background(), "", "p", "s", []int{1}, nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict || apiErr.Message != "p.x@y already exists" {
+		t.Fatalf("err = %#v", err)
+	}
+	if !IsStatus(err, http.StatusConflict) || IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("IsStatus mismatch for %v", err)
+	}
+}
+
+func TestDeleteMailbox_TransferBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/mailboxes/4" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["transfer_aliases_to"] != float64(9) {
+			t.Fatalf("body = %#v", body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"deleted": true})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if err := c.DeleteMailbox(context.Background(), 4, 9); err != nil {
+		t.Fatalf("DeleteMailbox err=%v", err)
+	}
+}
+
+func TestSearchAliases_PostsQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/aliases" || r.URL.Query().Get("page_id") != "1" {
+			t.Fatalf("%s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery)
+		}
+		var body searchAliasesRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Query != "netflix" {
+			t.Fatalf("body = %#v", body)
+		}
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 8, Email: "netflix@sl"}}})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	out, err := c.SearchAliases(context.Background(), 1, "netflix")
+	if err != nil {
+		t.Fatalf("SearchAliases err=%v", err)
+	}
+	if len(out.Aliases) != 1 || out.Aliases[0].ID != 8 {
+		t.Fatalf("out = %#v", out)
+	}
+}
+
+func TestStats_OK(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/stats" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"nb_alias":10,"nb_block":1,"nb_forward":20,"nb_reply":3}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	st, err := c.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats err=%v", err)
+	}
+	if st != (Stats{NbAlias: 10, NbBlock: 1, NbForward: 20, NbReply: 3}) {
+		t.Fatalf("stats = %#v", st)
+	}
+}
+
+func TestFindAliasByReverseAlias(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page_id")
+		switch r.URL.Path {
+		case "/api/v2/aliases":
+			if page != "0" {
+				_ = json.NewEncoder(w).Encode(AliasesResponse{})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 1, Email: "a@sl"}, {ID: 2, Email: "b@sl"}}})
+		case "/api/aliases/1/contacts", "/api/aliases/2/contacts":
+			if page != "0" || r.URL.Path == "/api/aliases/1/contacts" {
+				_ = json.NewEncoder(w).Encode(ContactsResponse{})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(ContactsResponse{Contacts: []Contact{{ID: 5, ReverseAliasAddress: "ra_x@sl.local"}}})
+		default:
+			t.Fatalf("unexpected %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	a, err := c.FindAliasByReverseAlias(context.Background(), "RA_X@sl.local")
+	if err != nil || a.ID != 2 {
+		t.Fatalf("FindAliasByReverseAlias = %#v, %v", a, err)
+	}
+}
+
+func TestCanCreateAlias(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/alias/options" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"can_create":false,"suffixes":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	ok, err := c.CanCreateAlias(context.Background())
+	if err != nil || ok {
+		t.Fatalf("CanCreateAlias = %v, %v", ok, err)
+	}
+}
+
+func TestSettings_Cached(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/setting" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		calls++
+		_, _ = w.Write([]byte(`{"alias_generator":"uuid","notification":true,"sender_format":"AT"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	for range 2 {
+		st, err := c.Settings(context.Background())
+		if err != nil || st.AliasGenerator != "uuid" || st.SenderFormat != "AT" {
+			t.Fatalf("Settings = %#v, %v", st, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestCreateRandomAlias_NameInBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "Shop" {
+			t.Fatalf("body = %#v", body)
+		}
+		if _, ok := body["note"]; ok {
+			t.Fatalf("empty note sent: %#v", body)
+		}
+		_ = json.NewEncoder(w).Encode(Alias{ID: 1, Email: "rand@sl"})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	name := "Shop"
+	if _, err := c.CreateRandomAlias(context.Background(), "", "", nil, &name); err != nil {
+		t.Fatalf("CreateRandomAlias err=%v", err)
+	}
+}
+
+func TestUpdateAliasName(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/aliases/3" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "Shop" {
+			t.Fatalf("body = %#v", body)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	name := "Shop"
+	if err := c.UpdateAliasName(context.Background(), 3, &name); err != nil {
+		t.Fatalf("UpdateAliasName err=%v", err)
+	}
+}
+
+func TestSignedSuffixFor(t *testing.T) {
+	opt := AliasOptionsResponse{Suffixes: []SuffixOption{{Suffix: ".a@sl", SignedSuffix: "sa"}, {Suffix: ".b@sl", SignedSuffix: "sb"}}}
+	if ss, err := opt.SignedSuffixFor(".b@sl"); err != nil || ss != "sb" {
+		t.Fatalf("SignedSuffixFor = %q, %v", ss, err)
+	}
+	_, err := opt.SignedSuffixFor(".c@sl")
+	if !errors.Is(err, ErrSuffixNotFound) || !strings.Contains(err.Error(), ".a@sl, .b@sl") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSetAliasPinned(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/aliases/6" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["pinned"] != false {
+			t.Fatalf("body = %#v", body)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if err := c.SetAliasPinned(context.Background(), 6, false); err != nil {
+		t.Fatalf("SetAliasPinned err=%v", err)
+	}
+}
+
+func TestCreateContact(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/aliases/5/contacts" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["contact"] != "friend@example.com" {
+			t.Fatalf("body = %#v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":9,"contact":"friend@example.com","creation_timestamp":1700000000,"reverse_alias":"\"friend\" <ra_x@sl>","reverse_alias_address":"ra_x@sl","existed":false}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	ct, err := c.CreateContact(context.Background(), 5, "friend@example.com")
+	if err != nil {
+		t.Fatalf("CreateContact err=%v", err)
+	}
+	if ct.ID != 9 || ct.ReverseAliasAddress != "ra_x@sl" || ct.CreationTimestamp != 1700000000 || ct.Existed {
+		t.Fatalf("contact = %#v", ct)
+	}
+}
+
+func TestAliasActivities(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/aliases/5/activities" || r.URL.Query().Get("page_id") != "1" {
+			t.Fatalf("%s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"activities":[{"action":"forward","from":"shop@example.com","to":"a@sl","timestamp":1700000000,"reverse_alias":"\"shop\" <ra@sl>"}]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.AliasActivities(context.Background(), 5, 1)
+	if err != nil {
+		t.Fatalf("AliasActivities err=%v", err)
+	}
+	want := []Activity{{Action: "forward", From: "shop@example.com", To: "a@sl", Timestamp: 1700000000, ReverseAlias: `"shop" <ra@sl>`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+}
+
+func TestGetAliasNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"Alias not found"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	_, err := c.GetAlias(context.Background(), 42)
+	if !errors.Is(err, ErrAliasNotFound) || !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("err = %v, want ErrAliasNotFound with the 404", err)
+	}
+	if err.Error() != "alias not found: no alias with id 42" {
+		t.Fatalf("message = %q", err.Error())
+	}
+}
+
+func TestUpdateAliasSendsOnlySetFields(t *testing.T) {
+	var body map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/aliases/8" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	empty, pin := "", true
+	if err := c.UpdateAlias(context.Background(), 8, AliasUpdate{Note: &empty, Pinned: &pin}); err != nil {
+		t.Fatalf("UpdateAlias err=%v", err)
+	}
+	if !reflect.DeepEqual(body, map[string]any{"note": "", "pinned": true}) {
+		t.Fatalf("body = %#v", body)
+	}
+}
+
+func TestUpdateAliasDisabledTogglesOnlyWhenNeeded(t *testing.T) {
+	enabled := true
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/toggle") {
+			enabled = !enabled
+		}
+		_ = json.NewEncoder(w).Encode(Alias{ID: 8, Enabled: enabled})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	off := true
+	for range 2 {
+		if err := c.UpdateAlias(context.Background(), 8, AliasUpdate{Disabled: &off}); err != nil {
+			t.Fatalf("UpdateAlias err=%v", err)
+		}
+	}
+	want := []string{"GET /api/aliases/8", "POST /api/aliases/8/toggle", "GET /api/aliases/8"}
+	if enabled || !reflect.DeepEqual(calls, want) {
+		t.Fatalf("enabled=%v calls=%v", enabled, calls)
+	}
+}
+
+func TestFindAliasesByEmail_StopsWhenAllFound(t *testing.T) {
+	pages := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 1, Email: "a@sl"}, {ID: 2, Email: "b@sl"}}})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.FindAliasesByEmail(context.Background(), []string{"a@sl", "b@sl"})
+	if err != nil || len(got) != 2 || got["b@sl"].ID != 2 || pages != 1 {
+		t.Fatalf("got %#v err=%v pages=%d", got, err, pages)
+	}
+}
+
+func TestFindAliasesByEmail_Normalizes(t *testing.T) {
+	pages := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		_ = json.NewEncoder(w).Encode(AliasesResponse{Aliases: []Alias{{ID: 1, Email: "Shop.X1@SL.lan"}, {ID: 2, Email: "b@sl"}}})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.FindAliasesByEmail(context.Background(), []string{"  shop.x1@sl.LAN ", "B@Sl"})
+	if err != nil || pages != 1 || len(got) != 2 || got["  shop.x1@sl.LAN "].ID != 1 || got["B@Sl"].ID != 2 {
+		t.Fatalf("got %#v err=%v pages=%d", got, err, pages)
+	}
+}
+
+func TestDomain(t *testing.T) {
+	if d := (Alias{Email: "shop.x1@Sl.Lan"}).Domain(); d != "sl.lan" {
+		t.Fatalf("Alias.Domain() = %q", d)
+	}
+	if d := (SuffixOption{Suffix: ".yeah@sl.lan"}).Domain(); d != "sl.lan" {
+		t.Fatalf("SuffixOption.Domain() = %q", d)
+	}
+	if d := (Alias{Email: "broken"}).Domain(); d != "" {
+		t.Fatalf("Domain() without @ = %q", d)
+	}
+}
+
+func TestCreate_NilVersusEmptyNoteOnTheWire(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = strings.TrimSpace(string(b))
+		_ = json.NewEncoder(w).Encode(Alias{ID: 1, Email: "a@sl"})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	ctx := context.Background()
+	empty := ""
+
+	_, _ = c.CreateRandomAlias(ctx, "", "", nil, nil)
+	if got != "" {
+		t.Fatalf("random nil note: body = %q, want none", got)
+	}
+	_, _ = c.CreateRandomAlias(ctx, "", "", &empty, nil)
+	if got != `{"note":""}` {
+		t.Fatalf("random empty note: body = %q", got)
+	}
+	_, _ = c.CreateCustomAlias(ctx, "", "p", "s", []int{1}, nil, nil)
+	if got != `{"alias_prefix":"p","signed_suffix":"s","mailbox_ids":[1]}` {
+		t.Fatalf("custom nil note: body = %q", got)
+	}
+	_, _ = c.CreateCustomAlias(ctx, "", "p", "s", []int{1}, &empty, nil)
+	if got != `{"alias_prefix":"p","signed_suffix":"s","mailbox_ids":[1],"note":""}` {
+		t.Fatalf("custom empty note: body = %q", got)
+	}
+}
+
+func TestAlias_MailboxesDecoded(t *testing.T) {
+	var a Alias
+	if err := json.Unmarshal([]byte(`{"id":1,"email":"a@sl","mailboxes":[{"id":3,"email":"me@example.com"},{"id":5,"email":"work@example.com"}]}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Mailboxes) != 2 || a.Mailboxes[1].Email != "work@example.com" {
+		t.Fatalf("mailboxes = %#v", a.Mailboxes)
+	}
+	if !a.HasMailbox(5) || a.HasMailbox(4) {
+		t.Fatalf("HasMailbox wrong for %#v", a.Mailboxes)
+	}
+}
+
+func TestListAllContacts_StopsOnEmptyPage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/aliases/4/contacts" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("page_id") {
+		case "0":
+			_ = json.NewEncoder(w).Encode(ContactsResponse{Contacts: []Contact{{ID: 1}, {ID: 2}}})
+		case "1":
+			_ = json.NewEncoder(w).Encode(ContactsResponse{Contacts: []Contact{{ID: 3}}})
+		case "2":
+			_ = json.NewEncoder(w).Encode(ContactsResponse{})
+		default:
+			t.Fatalf("fetched past the empty page: %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	got, err := c.ListAllContacts(context.Background(), 4)
+	if err != nil || len(got) != 3 || got[2].ID != 3 {
+		t.Fatalf("ListAllContacts = %#v, %v", got, err)
+	}
+}
+
+func TestAuthHeaderModes(t *testing.T) {
+	cases := []struct {
+		header string
+		bearer bool
+		want   map[string]string
+	}{
+		{"", false, map[string]string{"Authentication": "k", "Authorization": ""}},
+		{"Authorization", false, map[string]string{"Authorization": "k", "Authentication": ""}},
+		{"X-Api-Key", false, map[string]string{"X-Api-Key": "k", "Authentication": ""}},
+		{"", true, map[string]string{"Authorization": "Bearer k", "Authentication": ""}},
+		{"X-Api-Key", true, map[string]string{"X-Api-Key": "Bearer k", "Authorization": ""}},
+	}
+	for _, tc := range cases {
+		var got http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		c := NewClient(ts.URL, "k")
+		c.WithAuthHeader(tc.header)
+		c.WithBearerAuth(tc.bearer)
+		_, err := c.UserInfo(context.Background())
+		ts.Close()
+		if err != nil {
+			t.Fatalf("%+v: err = %v", tc, err)
+		}
+		for h, v := range tc.want {
+			if got.Get(h) != v {
+				t.Fatalf("header=%q bearer=%v: %s = %q, want %q", tc.header, tc.bearer, h, got.Get(h), v)
+			}
+		}
+	}
+}
+
+func TestCreateMailbox(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/mailboxes" {
+			t.Fatalf("%s %s", r.Method, r.URL.Path)
+		}
+		var body createMailboxRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Email != "x@example.com" {
+			t.Fatalf("body = %#v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":9,"email":"x@example.com","verified":false,"default":false}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	mb, err := c.CreateMailbox(context.Background(), "x@example.com")
+	if err != nil || mb.ID != 9 || mb.Verified {
+		t.Fatalf("CreateMailbox = %#v, %v", mb, err)
+	}
+}
+
+func TestAliasOptionsIDNHostname(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("hostname"); got != "xn--mnchen-3ya.de" {
+			t.Fatalf("hostname = %q", got)
+		}
+		if !strings.Contains(r.URL.RawQuery, "hostname=xn--mnchen-3ya.de") {
+			t.Fatalf("raw query = %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"can_create":true,"suffixes":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.AliasOptions(context.Background(), "München.de"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadOnlyBlocksMutations(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodGet {
+			t.Fatalf("%s %s reached the server", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"can_create":true,"suffixes":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithReadOnly(true)
+	if _, err := c.AliasOptions(context.Background(), ""); err != nil {
+		t.Fatalf("GET in read-only mode: %v", err)
+	}
+	if err := c.DeleteAlias(context.Background(), 1, ""); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("DeleteAlias err = %v, want ErrReadOnly", err)
+	}
+	if _, err := c.CreateRandomAlias(context.Background(), "", "", nil, nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("CreateRandomAlias err = %v, want ErrReadOnly", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestReadOnlyAllowsSearch(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/aliases" {
+			t.Fatalf("%s %s reached the server", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"aliases":[{"id":1,"email":"a@x.com"}]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithReadOnly(true)
+	res, err := c.SearchAliases(context.Background(), 0, "a@")
+	if err != nil {
+		t.Fatalf("SearchAliases in read-only mode: %v", err)
+	}
+	if len(res.Aliases) != 1 || res.Aliases[0].Email != "a@x.com" {
+		t.Fatalf("aliases = %+v", res.Aliases)
+	}
+	if _, err := c.CreateRandomAlias(context.Background(), "", "", nil, nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("CreateRandomAlias err = %v, want ErrReadOnly", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestTraceIDHeader(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(TraceHeader))
+		_, _ = w.Write([]byte(`{"can_create":true,"suffixes":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.AliasOptions(WithTraceID(context.Background(), "trace-1"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AliasOptions(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "trace-1" || got[1] != "" {
+		t.Fatalf("X-Trace-Id headers = %q", got)
+	}
+}
+
+func TestIsSignedSuffixExpired(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: 412, Message: "Alias creation time is expired, please retry"}, true},
+		{fmt.Errorf("create: %w", &APIError{StatusCode: 400, Message: "signed suffix expired"}), true},
+		{&APIError{StatusCode: 400, Message: "Tampered suffix"}, false},
+		{&APIError{StatusCode: 409, Message: "alias already exists"}, false},
+		{errors.New("expired"), false},
+	}
+	for _, tc := range cases {
+		if got := IsSignedSuffixExpired(tc.err); got != tc.want {
+			t.Errorf("IsSignedSuffixExpired(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestStatsNotFoundIsUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	_, err := c.Stats(context.Background())
+	if !errors.Is(err, ErrStatsUnavailable) || !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("err = %v, want ErrStatsUnavailable wrapping the 404", err)
+	}
+}
+
+func TestUpdateSettingsSendsOnlySetFields(t *testing.T) {
+	var gets int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/setting" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		if r.Method == http.MethodGet {
+			gets++
+			_, _ = w.Write([]byte(`{"sender_format":"AT"}`))
+			return
+		}
+		if r.Method != http.MethodPatch {
+			t.Fatalf("method = %s", r.Method)
+		}
+		b, _ := io.ReadAll(r.Body)
+		if got := strings.TrimSpace(string(b)); got != `{"sender_format":"NAME_ONLY"}` {
+			t.Fatalf("body = %s", got)
+		}
+		_, _ = w.Write([]byte(`{"sender_format":"NAME_ONLY","alias_generator":"word"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.Settings(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	format := "NAME_ONLY"
+	st, err := c.UpdateSettings(context.Background(), SettingsUpdate{SenderFormat: &format})
+	if err != nil || st.SenderFormat != "NAME_ONLY" {
+		t.Fatalf("UpdateSettings = %#v, %v", st, err)
+	}
+	st, err = c.Settings(context.Background())
+	if err != nil || st.SenderFormat != "NAME_ONLY" || gets != 1 {
+		t.Fatalf("Settings after update = %#v, %v (GETs %d)", st, err, gets)
+	}
+}
+
+func TestFindAliasByEmailNormalizesInput(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page_id") != "0" {
+			_, _ = w.Write([]byte(`{"aliases":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"aliases":[{"id":7,"email":"shop.x1@sl.lan"}]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	for _, in := range []string{"  shop.x1@sl.lan ", "SHOP.X1@SL.LAN", "\tShop.X1@sl.lan\n"} {
+		a, err := c.FindAliasByEmail(context.Background(), in)
+		if err != nil || a.ID != 7 {
+			t.Fatalf("FindAliasByEmail(%q) = %#v, %v", in, a, err)
+		}
+	}
+	if _, err := c.FindAliasByEmail(context.Background(), "not-an-email"); !errors.Is(err, ErrInvalidEmail) {
+		t.Fatalf("err = %v, want ErrInvalidEmail", err)
+	}
+}
+
+func TestStrictErrorDetection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error":"database is locked"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	if _, err := c.UserInfo(context.Background()); err != nil {
+		t.Fatalf("lenient client: err = %v, want nil", err)
+	}
+	c.WithStrictErrorDetection(true)
+	_, err := c.UserInfo(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 200 || apiErr.Message != "database is locked" {
+		t.Fatalf("strict client: err = %v", err)
+	}
+}
+
+func TestStrictErrorDetectionIgnoresNonStringError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"n","email":"e@x","error":null}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithStrictErrorDetection(true)
+	if ui, err := c.UserInfo(context.Background()); err != nil || ui.Email != "e@x" {
+		t.Fatalf("UserInfo = %#v, %v", ui, err)
+	}
+}
@@ -34,7 +34,7 @@ func TestIntegration_RandomAliasCreateAndDelete(t *testing.T) {
 	}
 	// Create random alias
 	note := "cli-itest"
-	a, err := c.CreateRandomAlias(ctx, "example.com", "word", &note)
+	a, err := c.CreateRandomAlias(ctx, "example.com", "word", &note, nil)
 	if err != nil {
 		t.Fatalf("CreateRandomAlias: %v", err)
 	}
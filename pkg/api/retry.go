@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidStatusCode = errors.New("invalid retry status code")
+
+// The retry policy of a new client: up to DefaultRetries more attempts,
+// waiting DefaultRetryBackoff, then twice as long, and so on.
+const (
+	DefaultRetries      = 3
+	DefaultRetryBackoff = 200 * time.Millisecond
+)
+
+// retryConfig controls how doJSON retries requests answered with a transient
+// error status or failing to connect. The zero value does not retry.
+type retryConfig struct {
+	max     int
+	backoff time.Duration
+	codes   map[int]bool // nil means 429 and every 5xx
+
+	noJitter bool
+
+	rateLimitWait time.Duration // wait after a 429 without Retry-After
+	maxRetryAfter time.Duration // longer Retry-After waits are not retried
+}
+
+// WithRetries makes the client retry a request up to n more times when the
+// server answers with a retryable status or cannot be reached, waiting
+// backoff, 2*backoff, 4*backoff... between attempts. Connection errors are
+// only retried for idempotent methods, unless the connection was never made.
+func (c *Client) WithRetries(n int, backoff time.Duration) {
+	c.retry.max = n
+	c.retry.backoff = backoff
+}
+
+// SetRetryPolicy is WithRetries: up to maxRetries more attempts, the first
+// after baseDelay. A new client uses DefaultRetries and DefaultRetryBackoff;
+// SetRetryPolicy(0, 0) turns retrying off.
+func (c *Client) SetRetryPolicy(maxRetries int, baseDelay time.Duration) {
+	c.WithRetries(maxRetries, baseDelay)
+}
+
+// WithRetryAfter sets how retries react to rate limiting: a 429 without a
+// Retry-After header is retried after fallback (2s by default), and a
+// Retry-After longer than max (a minute by default) is not waited for, so
+// the request fails with a *RateLimitError instead.
+func (c *Client) WithRetryAfter(fallback, max time.Duration) {
+	c.retry.rateLimitWait = fallback
+	c.retry.maxRetryAfter = max
+}
+
+// WithRetryJitter turns randomization of the retry backoff on or off (on by
+// default). With jitter each wait is between half and all of the nominal
+// backoff ("equal jitter"), so concurrent requests that were rejected
+// together do not all retry at the same instant.
+func (c *Client) WithRetryJitter(on bool) {
+	c.retry.noJitter = !on
+}
+
+// WithRetryStatusCodes replaces the statuses that trigger a retry, by default
+// 429 and every 5xx. Only 4xx and 5xx codes are accepted.
+func (c *Client) WithRetryStatusCodes(codes ...int) error {
+	set := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		if code < 400 || code > 599 {
+			return fmt.Errorf("%w: %d (want 400-599)", ErrInvalidStatusCode, code)
+		}
+		set[code] = true
+	}
+	c.retry.codes = set
+	return nil
+}
+
+// ParseStatusCodes parses a comma-separated list of HTTP status codes such as
+// "429,502,503", as accepted by WithRetryStatusCodes.
+func ParseStatusCodes(s string) ([]int, error) {
+	var codes []int
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 400 || n > 599 {
+			return nil, fmt.Errorf("%w: %q (want 400-599)", ErrInvalidStatusCode, f)
+		}
+		codes = append(codes, n)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("%w: empty list", ErrInvalidStatusCode)
+	}
+	return codes, nil
+}
+
+// shouldRetry reports whether the outcome of req, a response or a transport
+// error, is worth another attempt.
+func (c *Client) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err == nil {
+		return c.retryable(resp.StatusCode)
+	}
+	return retryableError(req, err)
+}
+
+// retryableError reports whether a transport error is worth retrying. A
+// failed dial never reached the server, so any request can be sent again;
+// other connection failures may have hit the server after it acted, so only
+// idempotent methods are retried.
+func retryableError(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return opErr != nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return false
+}
+
+func (c *Client) retryable(status int) bool {
+	if c.retry.codes == nil {
+		return status == http.StatusTooManyRequests || status >= 500
+	}
+	return c.retry.codes[status]
+}
+
+// retryWait returns how long to wait before retry number attempt (0-based)
+// after resp, which is nil after a transport error. The server's Retry-After
+// is honored when given, and a 429 without one waits the fixed rate-limit
+// delay. It reports false when the server asks for a longer wait than
+// maxRetryAfter.
+func (c *Client) retryWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp == nil {
+		return c.retryDelay(attempt), true
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return d, d <= c.retry.maxRetryAfter
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return c.retry.rateLimitWait, true
+	}
+	return c.retryDelay(attempt), true
+}
+
+// retryDelay returns how long to wait before retry number attempt (0-based).
+func (c *Client) retryDelay(attempt int) time.Duration {
+	d := c.retry.backoff << attempt
+	if c.retry.noJitter || d <= 1 {
+		return d
+	}
+	// math/rand/v2's top-level functions are randomly seeded and safe for
+	// concurrent use, so every request draws its own offset.
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date, relative to now; a time in the past means no wait. It
+// reports false when the header is missing or malformed.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(n)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// cloneForRetry returns a copy of req with a fresh body.
+func cloneForRetry(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
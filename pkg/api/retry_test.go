@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetry_DefaultCodes(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"n"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(2, 0)
+	if _, err := c.UserInfo(context.Background()); err != nil || calls != 3 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetry_DefaultPolicy(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	c := NewClientWithOptions(ts.URL, "k", ClientOptions{})
+	c.WithRetryJitter(false)
+	start := time.Now()
+	_, err := c.UserInfo(context.Background())
+	if !IsStatus(err, http.StatusBadGateway) || calls != 1+DefaultRetries {
+		t.Fatalf("err=%v calls=%d, want %d", err, calls, 1+DefaultRetries)
+	}
+	if d := time.Since(start); d < 7*DefaultRetryBackoff {
+		t.Fatalf("retries took %s, want the 200ms, 400ms, 800ms backoff", d)
+	}
+
+	calls = 0
+	c.SetRetryPolicy(0, 0)
+	if _, err := c.UserInfo(context.Background()); err == nil || calls != 1 {
+		t.Fatalf("SetRetryPolicy(0, 0): err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetry_CustomCodes(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(3, 0)
+	if err := c.WithRetryStatusCodes(408, 502); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.UserInfo(context.Background())
+	if !IsStatus(err, http.StatusServiceUnavailable) || calls != 1 {
+		t.Fatalf("503 not in set: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetry_ResendsBody(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body searchAliasesRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Query != "q" {
+			t.Fatalf("attempt %d body = %#v", calls, body)
+		}
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"aliases":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(1, 0)
+	if _, err := c.SearchAliases(context.Background(), 0, "q"); err != nil || calls != 2 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	got, err := ParseStatusCodes("429, 502,504")
+	if err != nil || len(got) != 3 || got[1] != 502 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "200", "abc", "429,600"} {
+		if _, err := ParseStatusCodes(bad); !errors.Is(err, ErrInvalidStatusCode) {
+			t.Fatalf("ParseStatusCodes(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestRetryDelay_Jitter(t *testing.T) {
+	c := NewClient("http://example.invalid", "k")
+	c.WithRetries(3, 100*time.Millisecond)
+	seen := map[time.Duration]bool{}
+	for range 20 {
+		d := c.retryDelay(1)
+		if d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Fatalf("delay %s outside [100ms, 200ms]", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("20 jittered delays were all %v", seen)
+	}
+
+	c.WithRetryJitter(false)
+	if d := c.retryDelay(2); d != 400*time.Millisecond {
+		t.Fatalf("delay without jitter = %s, want 400ms", d)
+	}
+}
+
+func TestRetry_RateLimitExhausted(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		if calls == 3 {
+			w.Header().Set("Retry-After", "30")
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"slow down"}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(2, 0)
+	_, err := c.UserInfo(context.Background())
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("err = %v (%T), want *RateLimitError", err, err)
+	}
+	if rl.Attempts != 3 || calls != 3 || rl.RetryAfter != 30*time.Second {
+		t.Fatalf("attempts=%d calls=%d retry-after=%s", rl.Attempts, calls, rl.RetryAfter)
+	}
+	if !IsStatus(err, http.StatusTooManyRequests) || rl.Err.Message != "slow down" {
+		t.Fatalf("wrapped error = %v", rl.Err)
+	}
+}
+
+func TestRetry_RetryAfterTooLong(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(2, 0)
+	_, err := c.UserInfo(context.Background())
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || calls != 1 || rl.RetryAfter != time.Hour {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetry_RateLimitFallbackWait(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	c.WithRetries(1, time.Hour)
+	c.WithRetryAfter(10*time.Millisecond, time.Minute)
+	start := time.Now()
+	if _, err := c.UserInfo(context.Background()); err != nil || calls != 2 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("waited %s, want the 10ms rate-limit fallback rather than the backoff", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"12", 12 * time.Second, true},
+		{"-3", 0, true},
+		{"soon", 0, false},
+		{"Wed, 01 May 2024 12:01:30 GMT", 90 * time.Second, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+	} {
+		if got, ok := parseRetryAfter(tc.in, now); got != tc.want || ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRetry_ConnectionDroppedOnlyForIdempotent(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		calls  int
+	}{{http.MethodGet, 2}, {http.MethodPost, 1}} {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		c := NewClient(ts.URL, "k")
+		c.WithRetries(2, 0)
+		req, err := c.newReq(context.Background(), tc.method, "/api/user_info", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.doJSON(req, nil)
+		ts.Close()
+		if calls != tc.calls || (tc.calls == 2) != (err == nil) {
+			t.Errorf("%s: calls=%d err=%v, want %d calls", tc.method, calls, err, tc.calls)
+		}
+	}
+}
+
+func TestRetry_DialErrorForAnyMethod(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	addr := ts.URL
+	ts.Close()
+	c := NewClient(addr, "k")
+	req, err := c.newReq(context.Background(), http.MethodPost, "/api/alias/random/new", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.do(req); !retryableError(req, err) {
+		t.Fatalf("dial error %v not retryable for POST", err)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Alias notes can carry key=value tags on top of free text. Each tag is a
+// line of its own, "tag:<key>=<value>", written after the text in key order:
+//
+//	Signed up for the newsletter
+//	tag:account=work
+//	tag:service=github
+//
+// Keys are letters, digits, '.', '-' and '_'; values are any text without a
+// newline. Every other line belongs to the text.
+const tagLinePrefix = "tag:"
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+var (
+	tagKey  = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	tagLine = regexp.MustCompile(`^tag:([A-Za-z0-9._-]+)=(.*)$`)
+)
+
+// ParseTag parses a "key=value" tag as given on the command line.
+func ParseTag(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !tagKey.MatchString(key) {
+		return "", "", fmt.Errorf("%w: %q: want key=value with a key of letters, digits, '.', '-' or '_'", ErrInvalidTag, s)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("%w: %q: the value must not contain a newline", ErrInvalidTag, s)
+	}
+	return key, value, nil
+}
+
+// ParseNoteTags splits a note into its free text and its tags.
+func ParseNoteTags(note string) (text string, tags map[string]string) {
+	tags = map[string]string{}
+	var lines []string
+	for _, l := range strings.Split(note, "\n") {
+		if m := tagLine.FindStringSubmatch(strings.TrimSuffix(l, "\r")); m != nil {
+			tags[m[1]] = m[2]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n"), tags
+}
+
+// FormatNoteTags joins text and tags into a note that ParseNoteTags reads back.
+func FormatNoteTags(text string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(text, "\n"))
+	for _, k := range keys {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(tagLinePrefix + k + "=" + tags[k])
+	}
+	return b.String()
+}
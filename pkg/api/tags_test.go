@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNoteTagsRoundTrip(t *testing.T) {
+	tags := map[string]string{"service": "github", "account": "work=main"}
+	note := FormatNoteTags("Signed up\nfor the newsletter\n", tags)
+	want := "Signed up\nfor the newsletter\ntag:account=work=main\ntag:service=github"
+	if note != want {
+		t.Fatalf("note = %q, want %q", note, want)
+	}
+	text, got := ParseNoteTags(note)
+	if text != "Signed up\nfor the newsletter" || !reflect.DeepEqual(got, tags) {
+		t.Fatalf("parsed text=%q tags=%v", text, got)
+	}
+	if note := FormatNoteTags("", map[string]string{"a": "1"}); note != "tag:a=1" {
+		t.Fatalf("tags only = %q", note)
+	}
+}
+
+func TestParseNoteTagsPlainNote(t *testing.T) {
+	text, tags := ParseNoteTags("tags: none here\nservice=github")
+	if text != "tags: none here\nservice=github" || len(tags) != 0 {
+		t.Fatalf("text=%q tags=%v", text, tags)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	if k, v, err := ParseTag("created-by=ci bot"); err != nil || k != "created-by" || v != "ci bot" {
+		t.Fatalf("got %q %q %v", k, v, err)
+	}
+	for _, s := range []string{"service", "=x", "a b=c", "k=line\nbreak"} {
+		if _, _, err := ParseTag(s); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("ParseTag(%q) err = %v, want ErrInvalidTag", s, err)
+		}
+	}
+}
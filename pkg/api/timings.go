@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointTiming aggregates the requests made to one endpoint.
+type EndpointTiming struct {
+	Endpoint string
+	Calls    int
+	Total    time.Duration
+}
+
+// Average returns the mean duration of a call.
+func (e EndpointTiming) Average() time.Duration {
+	if e.Calls == 0 {
+		return 0
+	}
+	return e.Total / time.Duration(e.Calls)
+}
+
+// Timings records how long requests take, grouped by method and path with
+// numeric IDs collapsed (e.g. "GET /api/aliases/:id"). It is safe for
+// concurrent use.
+type Timings struct {
+	mu    sync.Mutex
+	stats map[string]*EndpointTiming
+	order []string
+}
+
+func (t *Timings) record(req *http.Request, d time.Duration) {
+	key := req.Method + " " + endpointPath(req.URL.Path)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stats == nil {
+		t.stats = map[string]*EndpointTiming{}
+	}
+	e, ok := t.stats[key]
+	if !ok {
+		e = &EndpointTiming{Endpoint: key}
+		t.stats[key] = e
+		t.order = append(t.order, key)
+	}
+	e.Calls++
+	e.Total += d
+}
+
+// Summary returns the recorded endpoints in the order they were first called.
+func (t *Timings) Summary() []EndpointTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]EndpointTiming, 0, len(t.order))
+	for _, k := range t.order {
+		out = append(out, *t.stats[k])
+	}
+	return out
+}
+
+func endpointPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		if s != "" && strings.Trim(s, "0123456789") == "" {
+			parts[i] = ":id"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// WithTimings makes the client record the duration of every request into t.
+func (c *Client) WithTimings(t *Timings) {
+	c.timings = t
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTimings_GroupsByEndpoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Alias{ID: 1})
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	var tm Timings
+	c.WithTimings(&tm)
+	ctx := context.Background()
+	_, _ = c.GetAlias(ctx, 1)
+	_, _ = c.GetAlias(ctx, 22)
+	_, _ = c.UserInfo(ctx)
+
+	got := tm.Summary()
+	if len(got) != 2 {
+		t.Fatalf("summary = %#v", got)
+	}
+	if got[0].Endpoint != "GET /api/aliases/:id" || got[0].Calls != 2 {
+		t.Fatalf("first = %#v", got[0])
+	}
+	if got[1].Endpoint != "GET /api/user_info" || got[1].Calls != 1 {
+		t.Fatalf("second = %#v", got[1])
+	}
+	if got[0].Average() != got[0].Total/2 {
+		t.Fatalf("average = %v total = %v", got[0].Average(), got[0].Total)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestKeyPair writes a self-signed certificate and its key as PEM files.
+func writeTestKeyPair(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestWithClientCertificate_PresentsCert(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "cli-test" {
+			t.Fatalf("client certificate not presented")
+		}
+		_ = json.NewEncoder(w).Encode(UserInfo{Email: "m@tls"})
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "k")
+	// trust the test server's certificate
+	c.hc.Transport = ts.Client().Transport.(*http.Transport).Clone()
+	certFile, keyFile := writeTestKeyPair(t)
+	if err := c.WithClientCertificate(certFile, keyFile); err != nil {
+		t.Fatalf("WithClientCertificate err=%v", err)
+	}
+	ui, err := c.UserInfo(context.Background())
+	if err != nil {
+		t.Fatalf("UserInfo err=%v", err)
+	}
+	if ui.Email != "m@tls" {
+		t.Fatalf("ui = %#v", ui)
+	}
+}
+
+func TestWithClientCertificate_MissingFiles(t *testing.T) {
+	c := NewClient("https://example", "k")
+	err := c.WithClientCertificate("/nonexistent/cert.pem", "/nonexistent/key.pem")
+	if err == nil || !strings.Contains(err.Error(), "load client certificate") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+import "context"
+
+// TraceHeader is the request header carrying the trace id set by WithTraceID.
+const TraceHeader = "X-Trace-Id"
+
+type traceIDKey struct{}
+
+// WithTraceID returns a context whose requests carry id in the X-Trace-Id
+// header, so callers can correlate SimpleLogin calls with their own tracing.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the trace id stored by WithTraceID, or "".
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
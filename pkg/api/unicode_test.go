@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	emojiNote = "📬 shopping — Zürich & <friends> 🇨🇭"
+	emojiName = "Zoë 🐱 日本"
+)
+
+// echoAliasServer decodes the request body and echoes its note and name back in
+// the returned alias, failing if the text was escaped on the wire.
+func echoAliasServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		if !bytes.Contains(raw, []byte(emojiNote)) {
+			t.Fatalf("note not sent as raw UTF-8: %s", raw)
+		}
+		var body struct {
+			Note *string `json:"note"`
+			Name *string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatal(err)
+		}
+		_ = json.NewEncoder(w).Encode(Alias{ID: 1, Email: "u@sl", Note: body.Note, Name: body.Name})
+	}))
+}
+
+func TestCreateCustomAlias_PreservesUTF8(t *testing.T) {
+	ts := echoAliasServer(t)
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	note, name := emojiNote, emojiName
+	a, err := c.CreateCustomAlias(context.Background(), "", "p", "s", []int{1}, &note, &name)
+	if err != nil {
+		t.Fatalf("CreateCustomAlias err=%v", err)
+	}
+	if a.Note == nil || *a.Note != emojiNote || a.Name == nil || *a.Name != emojiName {
+		t.Fatalf("alias = %#v", a)
+	}
+}
+
+func TestCreateRandomAlias_PreservesUTF8(t *testing.T) {
+	ts := echoAliasServer(t)
+	defer ts.Close()
+	c := NewClient(ts.URL, "k")
+	note := emojiNote
+	a, err := c.CreateRandomAlias(context.Background(), "", "", &note, nil)
+	if err != nil {
+		t.Fatalf("CreateRandomAlias err=%v", err)
+	}
+	if a.Note == nil || *a.Note != emojiNote {
+		t.Fatalf("alias = %#v", a)
+	}
+}
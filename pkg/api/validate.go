@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// MaxPrefixLength mirrors the server-side limit on custom alias prefixes.
+const MaxPrefixLength = 40
+
+// MaxNameLength mirrors the server's 128-character alias name column.
+const MaxNameLength = 128
+
+// MaxNoteLength is the note length above which the CLI warns. The API
+// documents no limit for notes, which the server stores as unbounded text,
+// so it is a hint rather than a server rule.
+const MaxNoteLength = 4096
+
+var (
+	ErrInvalidPrefix = errors.New("invalid alias prefix")
+	ErrTooLong       = errors.New("value too long")
+	ErrInvalidEmail  = errors.New("invalid email address")
+)
+
+// ValidatePrefix checks a custom alias prefix against SimpleLogin's rules:
+// lowercase letters, digits, dots, dashes and underscores only, no leading or
+// trailing dot, and at most MaxPrefixLength characters.
+func ValidatePrefix(prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("%w: prefix is empty", ErrInvalidPrefix)
+	}
+	if len(prefix) > MaxPrefixLength {
+		return fmt.Errorf("%w: %q is %d characters, max is %d", ErrInvalidPrefix, prefix, len(prefix), MaxPrefixLength)
+	}
+	if strings.HasPrefix(prefix, ".") || strings.HasSuffix(prefix, ".") {
+		return fmt.Errorf("%w: %q must not start or end with a dot", ErrInvalidPrefix, prefix)
+	}
+	for _, r := range prefix {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+		default:
+			return fmt.Errorf("%w: %q contains %q (allowed: a-z, 0-9, '.', '-', '_')", ErrInvalidPrefix, prefix, r)
+		}
+	}
+	return nil
+}
+
+// ValidateLength checks that value is at most max characters (runes) long.
+// field names the value in the error, e.g. "note".
+func ValidateLength(field, value string, max int) error {
+	if n := utf8.RuneCountInString(value); n > max {
+		return fmt.Errorf("%w: %s is %d characters, %d over the limit of %d", ErrTooLong, field, n, n-max, max)
+	}
+	return nil
+}
+
+// secondLevelLabels are the common second-level labels used under two-letter
+// country TLDs (as in example.co.uk); PrefixFromHost skips them so it does not
+// need the full public suffix list.
+var secondLevelLabels = map[string]bool{"co": true, "com": true, "net": true, "org": true, "gov": true, "ac": true, "edu": true}
+
+// PrefixFromHost derives an alias prefix from a hostname's registrable domain,
+// e.g. "github" for "gist.github.com" or "bbc" for "www.bbc.co.uk", replacing
+// characters that are not allowed in prefixes with dashes. It returns "" when
+// nothing usable is left.
+func PrefixFromHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	labels := strings.Split(host, ".")
+	label := labels[0]
+	if n := len(labels); n >= 2 {
+		label = labels[n-2]
+		if n >= 3 && len(labels[n-1]) == 2 && secondLevelLabels[labels[n-2]] {
+			label = labels[n-3]
+		}
+	}
+	var b strings.Builder
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	p := strings.Trim(b.String(), "-")
+	if len(p) > MaxPrefixLength {
+		p = p[:MaxPrefixLength]
+	}
+	return p
+}
+
+// NormalizeHostname lowercases host, drops a trailing dot and converts
+// internationalized labels to their ASCII-compatible (punycode) form, so
+// "München.de" is sent as "xn--mnchen-3ya.de". Hostnames IDNA rejects are
+// passed through lowercased rather than dropped.
+func NormalizeHostname(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	return host
+}
+
+// NormalizeEmail trims and lowercases an email address given on the command
+// line and checks it is a bare address such as "shop.x1@sl.lan".
+func NormalizeEmail(email string) (string, error) {
+	e := strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(e); err != nil || addr.Address != e {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+	return e, nil
+}
+
+// AliasEmail returns the address a custom alias gets from prefix and a plain
+// suffix as listed in the alias options. Suffixes carry their own separator
+// (".yeah@sl.lan" gives "shop.yeah@sl.lan", "@example.com" on a custom domain
+// gives "shop@example.com"), so the two are joined as they are.
+func AliasEmail(prefix, suffix string) string {
+	return strings.ToLower(strings.TrimSpace(prefix) + strings.TrimSpace(suffix))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePrefix(t *testing.T) {
+	cases := []struct {
+		prefix string
+		ok     bool
+	}{
+		{"shop", true},
+		{"my.shop-2024_x", true},
+		{strings.Repeat("a", MaxPrefixLength), true},
+		{"", false},
+		{strings.Repeat("a", MaxPrefixLength+1), false},
+		{".shop", false},
+		{"shop.", false},
+		{"Shop", false},
+		{"my shop", false},
+		{"shop+1", false},
+	}
+	for _, tc := range cases {
+		err := ValidatePrefix(tc.prefix)
+		if tc.ok && err != nil {
+			t.Fatalf("ValidatePrefix(%q) err = %v", tc.prefix, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidPrefix) {
+			t.Fatalf("ValidatePrefix(%q) err = %v, want ErrInvalidPrefix", tc.prefix, err)
+		}
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	if err := ValidateLength("note", strings.Repeat("ü", 10), 10); err != nil {
+		t.Fatalf("10 runes within limit 10: err = %v", err)
+	}
+	err := ValidateLength("note", strings.Repeat("a", 13), 10)
+	if !errors.Is(err, ErrTooLong) || !strings.Contains(err.Error(), "3 over the limit of 10") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPrefixFromHost(t *testing.T) {
+	cases := map[string]string{
+		"github.com":       "github",
+		"gist.github.com":  "github",
+		"www.bbc.co.uk":    "bbc",
+		"Example.ORG.":     "example",
+		"localhost":        "localhost",
+		"xn--bcher-kva.ch": "xn--bcher-kva",
+		"my_shop.example":  "my_shop",
+		"":                 "",
+	}
+	for host, want := range cases {
+		if got := PrefixFromHost(host); got != want {
+			t.Errorf("PrefixFromHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestNormalizeHostname(t *testing.T) {
+	cases := map[string]string{
+		"München.de":       "xn--mnchen-3ya.de",
+		"GitHub.com.":      "github.com",
+		" example.org ":    "example.org",
+		"xn--bcher-kva.ch": "xn--bcher-kva.ch",
+		"my_shop.example":  "my_shop.example",
+		"":                 "",
+	}
+	for host, want := range cases {
+		if got := NormalizeHostname(host); got != want {
+			t.Errorf("NormalizeHostname(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	got, err := NormalizeEmail("  Shop.X1@SL.lan\n")
+	if err != nil || got != "shop.x1@sl.lan" {
+		t.Fatalf("NormalizeEmail = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "shop", "Shop <shop@sl.lan>", "a@b@c"} {
+		if _, err := NormalizeEmail(bad); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("NormalizeEmail(%q) err = %v, want ErrInvalidEmail", bad, err)
+		}
+	}
+}
+
+func TestAliasEmail(t *testing.T) {
+	cases := []struct{ prefix, suffix, want string }{
+		{"shop", ".yeah@sl.lan", "shop.yeah@sl.lan"},
+		{"shop", "@example.com", "shop@example.com"},
+		{" Shop ", " .x1@SL.lan", "shop.x1@sl.lan"},
+	}
+	for _, tc := range cases {
+		if got := AliasEmail(tc.prefix, tc.suffix); got != tc.want {
+			t.Errorf("AliasEmail(%q, %q) = %q, want %q", tc.prefix, tc.suffix, got, tc.want)
+		}
+	}
+}
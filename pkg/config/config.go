@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,7 +14,17 @@ const service = "simplelogincli"
 const user = "api_key"
 
 type Config struct {
+	// BaseURL may be a comma-separated list; the client tries the URLs in
+	// order when a connection fails.
 	BaseURL string `json:"base_url"`
+	// RememberLast makes random/custom record the created alias for the last command.
+	RememberLast bool `json:"remember_last,omitempty"`
+	// DefaultMailboxIDs are the mailboxes custom uses when no --mailbox-ids
+	// are given, instead of looking up the account's default mailbox.
+	DefaultMailboxIDs []int `json:"default_mailbox_ids,omitempty"`
+	// RequireExplicitMailbox makes custom fail without --mailbox-ids instead
+	// of picking a mailbox on its own.
+	RequireExplicitMailbox bool `json:"require_explicit_mailbox,omitempty"`
 }
 type SecureConfig struct {
 	BaseConfig Config `json:",inline"`
@@ -35,6 +47,15 @@ func userConfigFile() (string, error) {
 	return filepath.Join(dir, configFileName), nil
 }
 
+// Path returns the absolute location of the config file, whether or not it exists yet.
+func Path() (string, error) {
+	p, err := userConfigFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(p)
+}
+
 // Load reads config from file and applies environment overrides
 func Load() (SecureConfig, error) {
 	var cfg SecureConfig
@@ -69,6 +90,18 @@ func Load() (SecureConfig, error) {
 
 // Save writes config to file with 0600 permission
 func Save(cfg SecureConfig) error {
+	err := writeFile(cfg.BaseConfig)
+
+	if cfg.APIKey != "" {
+		if err := keyring.Set(service, user, cfg.APIKey); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func writeFile(c Config) error {
 	path, err := userConfigFile()
 	if err != nil {
 		return err
@@ -77,7 +110,7 @@ func Save(cfg SecureConfig) error {
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(cfg.BaseConfig, "", "  ")
+	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -87,14 +120,69 @@ func Save(cfg SecureConfig) error {
 	}
 	defer func() { _ = f.Close() }()
 	_, err = f.Write(data)
+	return err
+}
 
-	if cfg.APIKey != "" {
-		if err := keyring.Set(service, user, cfg.APIKey); err != nil {
-			return err
+// SaveDefaultMailboxIDs stores ids as the default mailboxes for new custom
+// aliases, keeping the rest of the config file as it is; nil clears them.
+// Unlike Save it does not pick up environment overrides.
+func SaveDefaultMailboxIDs(ids []int) error {
+	path, err := userConfigFile()
+	if err != nil {
+		return err
+	}
+	var c Config
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		c.BaseURL = DefaultBaseURL
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(b, &c); err != nil {
+			return fmt.Errorf("%s is not valid JSON: %w", path, err)
 		}
 	}
+	c.DefaultMailboxIDs = ids
+	return writeFile(c)
+}
 
-	return err
+// CheckFile reports whether the config file exists and, if it does, whether it
+// is readable and well-formed. Load ignores a malformed file; this surfaces it.
+func CheckFile() (exists bool, err error) {
+	path, err := userConfigFile()
+	if err != nil {
+		return false, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return true, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+	return true, nil
+}
+
+// Key sources reported by KeySource.
+const (
+	KeySourceEnv     = "SIMPLELOGIN_API_KEY"
+	KeySourceKeyring = "keyring"
+)
+
+// KeySource reports where Load takes the API key from, or "" if there is none.
+func KeySource() string {
+	if os.Getenv("SIMPLELOGIN_API_KEY") != "" {
+		return KeySourceEnv
+	}
+	if key, err := keyring.Get(service, user); err == nil && key != "" {
+		return KeySourceKeyring
+	}
+	return ""
 }
 
 func getenvDefault(key, def string) string {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -71,3 +72,84 @@ func TestSaveAndLoad_File(t *testing.T) {
 		t.Fatalf("config dir = %s, want under %s", filepath.Dir(p), filepath.Join(dir, configDirName))
 	}
 }
+
+func TestPath_UnderConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	if runtime.GOOS != "windows" {
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		defer os.Unsetenv("XDG_CONFIG_HOME")
+	}
+	p, err := Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if !filepath.IsAbs(p) {
+		t.Fatalf("Path() = %s, want absolute", p)
+	}
+	if runtime.GOOS != "windows" && p != filepath.Join(dir, configDirName, configFileName) {
+		t.Fatalf("Path() = %s, want under %s", p, dir)
+	}
+}
+
+func TestCheckFile_Malformed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on XDG_CONFIG_HOME")
+	}
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	if exists, err := CheckFile(); exists || err != nil {
+		t.Fatalf("missing file: exists=%v err=%v", exists, err)
+	}
+	p, _ := userConfigFile()
+	_ = os.MkdirAll(filepath.Dir(p), 0o700)
+	if err := os.WriteFile(p, []byte(`{"base_url":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := CheckFile(); !exists || err == nil {
+		t.Fatalf("malformed file: exists=%v err=%v", exists, err)
+	}
+}
+
+func TestKeySource(t *testing.T) {
+	keyring.MockInit()
+	os.Unsetenv("SIMPLELOGIN_API_KEY")
+	if got := KeySource(); got != "" {
+		t.Fatalf("no key: KeySource() = %q", got)
+	}
+	_ = keyring.Set(service, user, "k")
+	if got := KeySource(); got != KeySourceKeyring {
+		t.Fatalf("keyring: KeySource() = %q", got)
+	}
+	os.Setenv("SIMPLELOGIN_API_KEY", "env")
+	defer os.Unsetenv("SIMPLELOGIN_API_KEY")
+	if got := KeySource(); got != KeySourceEnv {
+		t.Fatalf("env: KeySource() = %q", got)
+	}
+}
+
+func TestSaveDefaultMailboxIDs_KeepsOtherSettings(t *testing.T) {
+	keyring.MockInit()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("SIMPLELOGIN_BASE_URL", "https://env.example")
+	p, err := userConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.MkdirAll(filepath.Dir(p), 0o700)
+	if err := os.WriteFile(p, []byte(`{"base_url":"https://host","remember_last":true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveDefaultMailboxIDs([]int{3, 5}); err != nil {
+		t.Fatalf("SaveDefaultMailboxIDs() error = %v", err)
+	}
+	b, _ := os.ReadFile(p)
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL != "https://host" || !c.RememberLast || len(c.DefaultMailboxIDs) != 2 || c.DefaultMailboxIDs[1] != 5 {
+		t.Fatalf("config = %#v", c)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const indexFileName = "index.json"
+
+// AliasIndex is a local snapshot of the account's aliases for offline search.
+// It is only as fresh as BuiltAt, and only belongs to the account whose API
+// key has the fingerprint in Account.
+type AliasIndex struct {
+	BuiltAt time.Time      `json:"built_at"`
+	BaseURL string         `json:"base_url"`
+	Account string         `json:"account"` // KeyFingerprint of the key it was built with
+	Aliases []IndexedAlias `json:"aliases"`
+}
+
+// IndexedAlias holds the alias fields search looks at.
+type IndexedAlias struct {
+	ID      int    `json:"id"`
+	Email   string `json:"email"`
+	Name    string `json:"name,omitempty"`
+	Note    string `json:"note,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// KeyFingerprint identifies the account an API key belongs to without storing
+// the key itself: the first 16 hex digits of its SHA-256.
+func KeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// IndexPath returns where the alias index lives, in the user cache dir.
+func IndexPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configDirName, indexFileName), nil
+}
+
+// SaveIndex writes idx with 0600 permission, replacing any previous index.
+func SaveIndex(idx AliasIndex) error {
+	path, err := IndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadIndex returns the stored index; ok is false when none has been built.
+func LoadIndex() (idx AliasIndex, ok bool, err error) {
+	path, err := IndexPath()
+	if err != nil {
+		return idx, false, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, false, nil
+	}
+	if err != nil {
+		return idx, false, err
+	}
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return idx, false, err
+	}
+	return idx, true, nil
+}
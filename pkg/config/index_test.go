@@ -0,0 +1,38 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIndex_SaveLoad(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CACHE_HOME")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, ok, err := LoadIndex(); err != nil || ok {
+		t.Fatalf("LoadIndex() before save: ok=%v err=%v", ok, err)
+	}
+	want := AliasIndex{
+		BuiltAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		BaseURL: "https://host",
+		Account: KeyFingerprint("key"),
+		Aliases: []IndexedAlias{{ID: 1, Email: "a@sl", Note: "shop", Enabled: true}, {ID: 2, Email: "b@sl"}},
+	}
+	if err := SaveIndex(want); err != nil {
+		t.Fatalf("SaveIndex() error = %v", err)
+	}
+	got, ok, err := LoadIndex()
+	if err != nil || !ok || !got.BuiltAt.Equal(want.BuiltAt) || got.BaseURL != want.BaseURL || got.Account != want.Account || len(got.Aliases) != 2 || got.Aliases[0] != want.Aliases[0] {
+		t.Fatalf("LoadIndex() = %#v ok=%v err=%v", got, ok, err)
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	a, b := KeyFingerprint("key-one"), KeyFingerprint("key-two")
+	if len(a) != 16 || a == b || a != KeyFingerprint("key-one") {
+		t.Fatalf("KeyFingerprint() = %q, %q", a, b)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const lastFileName = "last.json"
+
+// LastAlias is the most recently created alias, kept for quick recall.
+type LastAlias struct {
+	Email     string    `json:"email"`
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func lastAliasFile() (string, error) {
+	p, err := userConfigFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), lastFileName), nil
+}
+
+// SaveLastAlias records a into the config dir with 0600 permission.
+func SaveLastAlias(a LastAlias) error {
+	path, err := lastAliasFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadLastAlias returns the recorded alias; ok is false when none is recorded.
+func LoadLastAlias() (a LastAlias, ok bool, err error) {
+	path, err := lastAliasFile()
+	if err != nil {
+		return a, false, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, false, nil
+	}
+	if err != nil {
+		return a, false, err
+	}
+	if err := json.Unmarshal(b, &a); err != nil {
+		return a, false, err
+	}
+	return a, true, nil
+}
+
+// ClearLastAlias forgets the recorded alias. It is not an error if none is recorded.
+func ClearLastAlias() error {
+	path, err := lastAliasFile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLastAlias_SaveLoadClear(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on XDG_CONFIG_HOME")
+	}
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	if _, ok, err := LoadLastAlias(); err != nil || ok {
+		t.Fatalf("LoadLastAlias() before save: ok=%v err=%v", ok, err)
+	}
+	want := LastAlias{Email: "x@sl", ID: 7, CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	if err := SaveLastAlias(want); err != nil {
+		t.Fatalf("SaveLastAlias() error = %v", err)
+	}
+	got, ok, err := LoadLastAlias()
+	if err != nil || !ok || got.Email != want.Email || got.ID != want.ID || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Fatalf("LoadLastAlias() = %#v ok=%v err=%v", got, ok, err)
+	}
+	if err := ClearLastAlias(); err != nil {
+		t.Fatalf("ClearLastAlias() error = %v", err)
+	}
+	if err := ClearLastAlias(); err != nil {
+		t.Fatalf("second ClearLastAlias() error = %v", err)
+	}
+	if _, ok, _ := LoadLastAlias(); ok {
+		t.Fatalf("alias still recorded after clear")
+	}
+}
@@ -0,0 +1,321 @@
+// Package importer reads password-manager exports and picks out the entries
+// whose login is an email address, so they can be recreated as aliases.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// Supported export formats.
+const (
+	FormatBitwarden = "bitwarden"
+	Format1Password = "1password"
+)
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Entry is a login whose username is an email address.
+type Entry struct {
+	Site  string
+	Email string
+
+	item *exportItem // where the entry came from, for WriteRetryFile
+}
+
+// exportItem is an export item as read, so it can be written back unchanged.
+type exportItem struct {
+	src    *source
+	record []string        // the CSV row
+	json   json.RawMessage // the Bitwarden JSON item
+}
+
+// source is the export the items were read from.
+type source struct {
+	header []string                   // CSV header row; nil for a JSON export
+	top    map[string]json.RawMessage // JSON export fields other than the items
+}
+
+// Skipped is an export item that could not be turned into an Entry.
+type Skipped struct {
+	Item   string
+	Reason string
+}
+
+// Parse reads an export in the given format. Bitwarden exports may be JSON or
+// CSV (detected from the content); 1Password exports are CSV. Items without an
+// email login are returned in the skipped list rather than failing the parse.
+func Parse(format string, r io.Reader) ([]Entry, []Skipped, error) {
+	br := bufio.NewReader(r)
+	switch strings.ToLower(format) {
+	case FormatBitwarden:
+		if startsWithBrace(br) {
+			return parseBitwardenJSON(br)
+		}
+		return parseCSV(br, []string{"login_username"}, []string{"login_uri"}, []string{"name"})
+	case Format1Password:
+		return parseCSV(br, []string{"username"}, []string{"url", "website"}, []string{"title"})
+	}
+	return nil, nil, fmt.Errorf("%w %q (want %s or %s)", ErrUnknownFormat, format, FormatBitwarden, Format1Password)
+}
+
+func startsWithBrace(br *bufio.Reader) bool {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = br.ReadByte()
+			continue
+		}
+		return b[0] == '{'
+	}
+}
+
+type bitwardenItem struct {
+	Type  int    `json:"type"`
+	Name  string `json:"name"`
+	Login *struct {
+		Username string `json:"username"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+}
+
+func parseBitwardenJSON(r io.Reader) ([]Entry, []Skipped, error) {
+	var top map[string]json.RawMessage
+	var raw []json.RawMessage
+	err := json.NewDecoder(r).Decode(&top)
+	if err == nil {
+		err = json.Unmarshal(top["items"], &raw)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse bitwarden json: %w", err)
+	}
+	delete(top, "items")
+	src := &source{top: top}
+	var entries []Entry
+	var skipped []Skipped
+	for _, data := range raw {
+		var it bitwardenItem
+		if err := json.Unmarshal(data, &it); err != nil {
+			return nil, nil, fmt.Errorf("parse bitwarden json: %w", err)
+		}
+		if it.Login == nil {
+			skipped = append(skipped, Skipped{Item: it.Name, Reason: "not a login item"})
+			continue
+		}
+		uri := ""
+		if len(it.Login.URIs) > 0 {
+			uri = it.Login.URIs[0].URI
+		}
+		e, reason := newEntry(it.Login.Username, uri, it.Name)
+		if reason != "" {
+			skipped = append(skipped, Skipped{Item: it.Name, Reason: reason})
+			continue
+		}
+		e.item = &exportItem{src: src, json: data}
+		entries = append(entries, e)
+	}
+	return entries, skipped, nil
+}
+
+// parseCSV reads a CSV export with a header row; each column argument lists
+// the accepted header names, case-insensitively.
+func parseCSV(r io.Reader, userCols, urlCols, nameCols []string) ([]Entry, []Skipped, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+	userIdx, urlIdx, nameIdx := column(header, userCols), column(header, urlCols), column(header, nameCols)
+	if userIdx < 0 {
+		return nil, nil, fmt.Errorf("csv header has no %s column", strings.Join(userCols, "/"))
+	}
+	src := &source{header: header}
+	var entries []Entry
+	var skipped []Skipped
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			skipped = append(skipped, Skipped{Item: fmt.Sprintf("line %d", line), Reason: err.Error()})
+			continue
+		}
+		line, _ = cr.FieldPos(0)
+		name := field(rec, nameIdx)
+		item := name
+		if item == "" {
+			item = fmt.Sprintf("line %d", line)
+		}
+		e, reason := newEntry(field(rec, userIdx), field(rec, urlIdx), name)
+		if reason != "" {
+			skipped = append(skipped, Skipped{Item: item, Reason: reason})
+			continue
+		}
+		e.item = &exportItem{src: src, record: rec}
+		entries = append(entries, e)
+	}
+	return entries, skipped, nil
+}
+
+func column(header, names []string) int {
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		for _, n := range names {
+			if strings.EqualFold(h, n) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func field(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+// newEntry builds an Entry, or returns why the item is skipped.
+func newEntry(username, uri, name string) (Entry, string) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return Entry{}, "no username"
+	}
+	addr, err := mail.ParseAddress(username)
+	if err != nil || addr.Address != username {
+		return Entry{}, "username is not an email address"
+	}
+	site := siteOf(uri)
+	if site == "" {
+		site = strings.TrimSpace(name)
+	}
+	return Entry{Site: site, Email: username}, ""
+}
+
+// siteOf returns the host of uri, accepting bare hostnames as well.
+func siteOf(uri string) string {
+	uri = strings.TrimSpace(uri)
+	if uri == "" {
+		return ""
+	}
+	if !strings.Contains(uri, "://") {
+		uri = "https://" + uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// Failed is an entry whose alias could not be created.
+type Failed struct {
+	Entry
+	Err string
+}
+
+var ErrMixedSources = errors.New("entries come from different exports")
+
+// ErrorField is the key WriteRetryFile adds to each Bitwarden JSON item to
+// hold the error it failed with. Parse ignores it.
+const ErrorField = "simplelogin_import_error"
+
+// WriteRetryFile writes the items of failed entries in the format of the
+// export they were read from, with the same CSV header or the same JSON
+// fields around the items, so the failures can be imported again. Each item
+// carries its error: a "# <error>" comment line before a CSV row, or an
+// ErrorField key in a JSON item. Every entry must come from the same call to
+// Parse.
+func WriteRetryFile(w io.Writer, failed []Failed) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	var src *source
+	for _, f := range failed {
+		if f.item == nil {
+			return fmt.Errorf("retry file: entry %s was not read by Parse", f.Email)
+		}
+		if src != nil && f.item.src != src {
+			return ErrMixedSources
+		}
+		src = f.item.src
+	}
+	if src.header == nil {
+		items := make([]json.RawMessage, len(failed))
+		for i, f := range failed {
+			item, err := withError(f.item.json, f.Err)
+			if err != nil {
+				return fmt.Errorf("retry file: entry %s: %w", f.Email, err)
+			}
+			items[i] = item
+		}
+		top := make(map[string]any, len(src.top)+1)
+		for k, v := range src.top {
+			top[k] = v
+		}
+		top["items"] = items
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(top)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(src.header); err != nil {
+		return err
+	}
+	for _, f := range failed {
+		if f.Err != "" {
+			// Flush first so the comment lands right before its row.
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "# %s\n", oneLine(f.Err)); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(f.item.record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// withError returns the JSON item with ErrorField set to msg.
+func withError(item json.RawMessage, msg string) (json.RawMessage, error) {
+	if msg == "" {
+		return item, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return nil, err
+	}
+	v, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	fields[ErrorField] = v
+	return json.Marshal(fields)
+}
+
+// oneLine joins the lines of s so it fits in a single CSV comment.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParse_BitwardenJSON(t *testing.T) {
+	in := `{"items":[
+		{"type":1,"name":"Shop","login":{"username":"shop@fwd.example","uris":[{"uri":"https://www.shop.example/login"}]}},
+		{"type":1,"name":"Forum","login":{"username":"bob","uris":[]}},
+		{"type":2,"name":"Secure note"}
+	]}`
+	entries, skipped, err := Parse(FormatBitwarden, strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse err = %v", err)
+	}
+	if len(entries) != 1 || !entryIs(entries[0], "www.shop.example", "shop@fwd.example") {
+		t.Fatalf("entries = %#v", entries)
+	}
+	if len(skipped) != 2 || skipped[0].Item != "Forum" || skipped[1].Reason != "not a login item" {
+		t.Fatalf("skipped = %#v", skipped)
+	}
+}
+
+func TestParse_BitwardenCSV(t *testing.T) {
+	in := "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+		",,login,News,,,0,news.example,news@fwd.example,pw,\n" +
+		",,login,Bank,,,0,https://bank.example,,pw,\n"
+	entries, skipped, err := Parse(FormatBitwarden, strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse err = %v", err)
+	}
+	if len(entries) != 1 || !entryIs(entries[0], "news.example", "news@fwd.example") {
+		t.Fatalf("entries = %#v", entries)
+	}
+	if len(skipped) != 1 || skipped[0] != (Skipped{Item: "Bank", Reason: "no username"}) {
+		t.Fatalf("skipped = %#v", skipped)
+	}
+}
+
+func TestParse_1PasswordCSV(t *testing.T) {
+	in := "\ufeffTitle,Url,Username,Password,Notes\n" +
+		"Mail list,,list@fwd.example,pw,\n"
+	entries, _, err := Parse(Format1Password, strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse err = %v", err)
+	}
+	if len(entries) != 1 || !entryIs(entries[0], "Mail list", "list@fwd.example") {
+		t.Fatalf("entries = %#v", entries)
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	if _, _, err := Parse("lastpass", strings.NewReader("")); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// entryIs reports whether e is the login for email on site.
+func entryIs(e Entry, site, email string) bool {
+	return e.Site == site && e.Email == email
+}
+
+func TestWriteRetryFile_KeepsCSVFormat(t *testing.T) {
+	in := "\ufeffTitle,Url,Username,Password,Notes\n" +
+		"Shop,shop.example,shop@fwd.example,pw1,\n" +
+		"News,,news@fwd.example,pw2,\"daily, at 8\"\n"
+	entries, _, err := Parse(Format1Password, strings.NewReader(in))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Parse = %#v, %v", entries, err)
+	}
+	var b strings.Builder
+	if err := WriteRetryFile(&b, []Failed{{Entry: entries[1], Err: "HTTP 429:\nrate limited"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeffTitle,Url,Username,Password,Notes\n" +
+		"# HTTP 429: rate limited\n" +
+		"News,,news@fwd.example,pw2,\"daily, at 8\"\n"
+	if b.String() != want {
+		t.Fatalf("retry file = %q, want %q", b.String(), want)
+	}
+	again, _, err := Parse(Format1Password, strings.NewReader(b.String()))
+	if err != nil || len(again) != 1 || !entryIs(again[0], "News", "news@fwd.example") {
+		t.Fatalf("re-parsed = %#v, %v", again, err)
+	}
+}
+
+func TestWriteRetryFile_KeepsBitwardenJSON(t *testing.T) {
+	in := `{"encrypted":false,"folders":[],"items":[
+		{"type":1,"name":"Shop","login":{"username":"shop@fwd.example","uris":[{"uri":"https://shop.example"}],"password":"pw1"}},
+		{"type":1,"name":"News","login":{"username":"news@fwd.example","uris":[],"password":"pw2"}}
+	]}`
+	entries, _, err := Parse(FormatBitwarden, strings.NewReader(in))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Parse = %#v, %v", entries, err)
+	}
+	var b strings.Builder
+	if err := WriteRetryFile(&b, []Failed{{Entry: entries[0], Err: "timeout"}}); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Encrypted *bool            `json:"encrypted"`
+		Folders   []any            `json:"folders"`
+		Items     []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &out); err != nil {
+		t.Fatalf("retry file is not JSON: %v\n%s", err, b.String())
+	}
+	if out.Encrypted == nil || out.Folders == nil || len(out.Items) != 1 || out.Items[0]["login"].(map[string]any)["password"] != "pw1" {
+		t.Fatalf("retry file = %s", b.String())
+	}
+	if out.Items[0][ErrorField] != "timeout" {
+		t.Fatalf("%s = %v, want timeout", ErrorField, out.Items[0][ErrorField])
+	}
+	again, _, err := Parse(FormatBitwarden, strings.NewReader(b.String()))
+	if err != nil || len(again) != 1 || !entryIs(again[0], "shop.example", "shop@fwd.example") {
+		t.Fatalf("re-parsed = %#v, %v", again, err)
+	}
+}
+
+func TestWriteRetryFile_CSVRoundTrip(t *testing.T) {
+	in := "name,login_uri,login_username,login_password\n" +
+		"A,a.example,a@fwd.example,pw1\n" +
+		"B,b.example,b@fwd.example,pw2\n" +
+		"C,c.example,c@fwd.example,pw3\n"
+	entries, _, err := Parse(FormatBitwarden, strings.NewReader(in))
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Parse = %#v, %v", entries, err)
+	}
+	var b strings.Builder
+	failed := []Failed{{Entry: entries[0], Err: "HTTP 409"}, {Entry: entries[2], Err: "timeout"}}
+	if err := WriteRetryFile(&b, failed); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "# HTTP 409\nA,") || !strings.Contains(b.String(), "# timeout\nC,") {
+		t.Fatalf("retry file = %q, want an error comment before each row", b.String())
+	}
+	again, skipped, err := Parse(FormatBitwarden, strings.NewReader(b.String()))
+	if err != nil || len(skipped) != 0 || len(again) != 2 ||
+		!entryIs(again[0], "a.example", "a@fwd.example") || !entryIs(again[1], "c.example", "c@fwd.example") {
+		t.Fatalf("re-parsed = %#v, %#v, %v", again, skipped, err)
+	}
+}
+
+func TestWriteRetryFile_MixedSources(t *testing.T) {
+	a, _, _ := Parse(Format1Password, strings.NewReader("Title,Url,Username\nA,,a@fwd.example\n"))
+	b, _, _ := Parse(Format1Password, strings.NewReader("Title,Url,Username\nB,,b@fwd.example\n"))
+	err := WriteRetryFile(io.Discard, []Failed{{Entry: a[0]}, {Entry: b[0]}})
+	if !errors.Is(err, ErrMixedSources) {
+		t.Fatalf("err = %v, want ErrMixedSources", err)
+	}
+}